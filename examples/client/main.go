@@ -37,14 +37,13 @@ func main() {
 		}
 	}()
 
-	// Process only security-relevant warnings/errors
+	// Process only security-relevant warnings/errors; entries below the
+	// client's level are never delivered, so no re-check is needed here.
 	go func() {
 		for {
 			entry := securityLogs.Get()
-			if entry.Level == "ERROR" || entry.Level == "WARN" {
-				fmt.Printf("🚨 SECURITY ALERT [%s] %s: %s\n",
-					entry.Namespace, entry.Level, entry.Output)
-			}
+			fmt.Printf("🚨 SECURITY ALERT [%s] %s: %s\n",
+				entry.Namespace, entry.Level, entry.Output)
 		}
 	}()
 
@@ -158,8 +158,10 @@ func BenchmarkNamespaceFiltering(b *testing.B) {
 
 // BenchmarkFileInfo measures the cost of runtime.Caller for file info.
 func BenchmarkFileInfo(b *testing.B) {
+	h := NewHub()
+	var e Entry
 	for i := 0; i < b.N; i++ {
-		fileInfo(1)
+		h.setCaller(&e, CallerDefault, 1)
 	}
 }
 
@@ -169,3 +171,74 @@ func BenchmarkEntryCreation(b *testing.B) {
 		_ = fmt.Sprint("benchmark message ", i)
 	}
 }
+
+// BenchmarkFanOut measures a single logging goroutine dispatching to an
+// increasing number of attached clients.
+func BenchmarkFanOut(b *testing.B) {
+	for _, n := range []int{1, 10, 50, 100} {
+		b.Run(fmt.Sprintf("clients=%d", n), func(b *testing.B) {
+			l := NewLogger(benchNS)
+			stops := make([]func(), n)
+			for i := range stops {
+				_, stops[i] = benchClient(benchNS)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Info("benchmark fan-out")
+			}
+			b.StopTimer()
+			for _, stop := range stops {
+				stop()
+			}
+		})
+	}
+}
+
+// BenchmarkParallelFanOut measures many logging goroutines dispatching to
+// many attached clients at once, the case where a global dispatch lock
+// serializes callers.
+func BenchmarkParallelFanOut(b *testing.B) {
+	for _, n := range []int{10, 50} {
+		b.Run(fmt.Sprintf("clients=%d", n), func(b *testing.B) {
+			l := NewLogger(benchNS)
+			stops := make([]func(), n)
+			for i := range stops {
+				_, stops[i] = benchClient(benchNS)
+			}
+			b.SetParallelism(8)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					l.Info("benchmark parallel fan-out")
+				}
+			})
+			b.StopTimer()
+			for _, stop := range stops {
+				stop()
+			}
+		})
+	}
+}
+
+// BenchmarkParallelFilteredClients measures many logging goroutines when
+// most attached clients filter the namespace out, so dispatch cost is
+// dominated by walking the subscriber list.
+func BenchmarkParallelFilteredClients(b *testing.B) {
+	const numClients = 50
+	l := NewLogger(benchNS)
+	stops := make([]func(), numClients)
+	for i := range stops {
+		_, stops[i] = benchClient(fmt.Sprintf("other-ns-%d", i))
+	}
+	b.SetParallelism(8)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info("filtered parallel message")
+		}
+	})
+	b.StopTimer()
+	for _, stop := range stops {
+		stop()
+	}
+}
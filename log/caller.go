@@ -0,0 +1,162 @@
+package log
+
+import (
+	"path"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CallerPolicy decides how the call site of an entry is recorded.
+type CallerPolicy int
+
+const (
+	// CallerDefault defers to the Hub's policy, which is CallerBasename
+	// unless changed with SetCallerPolicy.
+	CallerDefault CallerPolicy = iota
+	// CallerBasename records the file's base name, e.g. "handler.go:42".
+	CallerBasename
+	// CallerModuleRelative records the file's path within its module,
+	// e.g. "internal/api/handler.go:42".
+	CallerModuleRelative
+	// CallerFull records the file's full path.
+	CallerFull
+	// CallerOff skips the runtime.Caller lookup entirely, leaving File,
+	// Function and Package empty.
+	CallerOff
+)
+
+// SetCallerPolicy sets how the default Hub records call sites. See
+// [Hub.SetCallerPolicy].
+func SetCallerPolicy(p CallerPolicy) {
+	defaultHub.SetCallerPolicy(p)
+}
+
+// SetCallerPolicy sets how loggers of h record the call site of each entry
+// in [Entry.File], [Entry.Function] and [Entry.Package]. Loggers created
+// with [WithCallerPolicy] keep their own policy.
+func (h *Hub) SetCallerPolicy(p CallerPolicy) {
+	h.callerPolicy.Store(int32(p))
+}
+
+// WithCallerPolicy makes the Logger record call sites according to p
+// instead of its Hub's policy, e.g. CallerOff for a logger on a hot path.
+func WithCallerPolicy(p CallerPolicy) LoggerOption {
+	return func(l *Logger) {
+		l.caller = p
+	}
+}
+
+// FillCaller sets e's call site from pc, a program counter such as
+// slog.Record.PC, according to h's policy. It does nothing when pc is zero
+// or the policy is CallerOff.
+func (h *Hub) FillCaller(e *Entry, pc uintptr) {
+	policy := h.resolveCaller(CallerDefault)
+	if pc == 0 || policy == CallerOff {
+		return
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	e.setFrame(frame, policy)
+}
+
+func (h *Hub) resolveCaller(p CallerPolicy) CallerPolicy {
+	if p == CallerDefault {
+		p = CallerPolicy(h.callerPolicy.Load())
+	}
+	return p
+}
+
+// setCaller records the call site skip frames above setCaller, like
+// runtime.Caller(skip) would from the caller of setCaller.
+func (h *Hub) setCaller(e *Entry, p CallerPolicy, skip int) {
+	policy := h.resolveCaller(p)
+	if policy == CallerOff {
+		return
+	}
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		e.File = "<???>:1"
+		return
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	e.setFrame(frame, policy)
+}
+
+// setCaller records the call site of a Logger method skip frames up.
+func (l Logger) setCaller(e *Entry, skip int) {
+	l.getHub().setCaller(e, l.caller, skip+1)
+}
+
+func (e *Entry) setFrame(frame runtime.Frame, policy CallerPolicy) {
+	pkg, function := splitFunction(frame.Function)
+	e.Function = function
+	e.Package = pkg
+	file := frame.File
+	switch policy {
+	case CallerModuleRelative:
+		file = moduleRelative(pkg, file)
+	case CallerFull:
+	default:
+		file = path.Base(file)
+	}
+	e.File = file + ":" + strconv.Itoa(frame.Line)
+}
+
+// splitFunction splits a fully qualified function name such as
+// "github.com/a/b/api.(*Server).handle" into its package path and the
+// function name within the package.
+func splitFunction(name string) (pkg, function string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	dot += slash + 1
+	// Dots in the last path element are escaped in symbol names.
+	return strings.ReplaceAll(name[:dot], "%2e", "."), name[dot+1:]
+}
+
+// moduleRelative returns file's path relative to the root of the module
+// containing pkg. Standard library files are relative to GOROOT/src, and
+// files of package main, whose import path is not recorded, are reduced to
+// their base name.
+func moduleRelative(pkg, file string) string {
+	base := path.Base(file)
+	if pkg == "" || pkg == "main" {
+		return base
+	}
+	dir := pkg
+	for _, mod := range buildModules() {
+		if pkg == mod {
+			return base
+		}
+		if strings.HasPrefix(pkg, mod+"/") {
+			dir = pkg[len(mod)+1:]
+			break
+		}
+	}
+	return dir + "/" + base
+}
+
+// buildModules lists the module paths compiled into the binary, longest
+// first so nested modules match before their parents.
+var buildModules = sync.OnceValue(func() []string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	var mods []string
+	if info.Main.Path != "" {
+		mods = append(mods, info.Main.Path)
+	}
+	for _, dep := range info.Deps {
+		mods = append(mods, dep.Path)
+	}
+	slices.SortFunc(mods, func(a, b string) int {
+		return len(b) - len(a)
+	})
+	return mods
+})
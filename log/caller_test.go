@@ -0,0 +1,101 @@
+package log
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCallerPolicies(t *testing.T) {
+	t.Parallel()
+	_, self, _, _ := runtime.Caller(0)
+	tests := []struct {
+		policy CallerPolicy
+		prefix string
+	}{
+		{CallerDefault, "caller_test.go:"},
+		{CallerBasename, "caller_test.go:"},
+		{CallerModuleRelative, "log/caller_test.go:"},
+		{CallerFull, filepath.ToSlash(self) + ":"},
+	}
+	for _, tt := range tests {
+		h := NewHub()
+		h.SetCallerPolicy(tt.policy)
+		c := h.CreateClient()
+		h.NewLogger("caller").Info("hello")
+		e, ok := getEntry(c, time.Second)
+		c.Destroy()
+		if !ok {
+			t.Fatalf("policy %d: timed out", tt.policy)
+		}
+		if !strings.HasPrefix(e.File, tt.prefix) {
+			t.Errorf("policy %d: file = %q, want prefix %q", tt.policy, e.File, tt.prefix)
+		}
+		if e.Function != "TestCallerPolicies" {
+			t.Errorf("policy %d: function = %q, want TestCallerPolicies", tt.policy, e.Function)
+		}
+		if e.Package != "github.com/taigrr/log-socket/v2/log" {
+			t.Errorf("policy %d: package = %q", tt.policy, e.Package)
+		}
+	}
+}
+
+func TestCallerOff(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+
+	h.NewLogger("caller", WithCallerPolicy(CallerOff)).Info("hot path")
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.File != "" || e.Function != "" || e.Package != "" {
+		t.Errorf("caller recorded with CallerOff: %q %q %q", e.File, e.Function, e.Package)
+	}
+
+	// The Logger's own policy overrides the Hub's.
+	h.SetCallerPolicy(CallerOff)
+	h.NewLogger("caller", WithCallerPolicy(CallerBasename)).Info("overridden")
+	e, _ = getEntry(c, time.Second)
+	if !strings.HasPrefix(e.File, "caller_test.go:") {
+		t.Errorf("file = %q, want caller_test.go", e.File)
+	}
+}
+
+func TestSplitFunction(t *testing.T) {
+	tests := []struct {
+		name, pkg, function string
+	}{
+		{"main.main", "main", "main"},
+		{"github.com/a/b/api.(*Server).handle", "github.com/a/b/api", "(*Server).handle"},
+		{"gopkg.in/yaml%2ev3.Unmarshal", "gopkg.in/yaml.v3", "Unmarshal"},
+		{"net/http.HandlerFunc.ServeHTTP", "net/http", "HandlerFunc.ServeHTTP"},
+		{"github.com/a/b.init.func1", "github.com/a/b", "init.func1"},
+	}
+	for _, tt := range tests {
+		pkg, function := splitFunction(tt.name)
+		if pkg != tt.pkg || function != tt.function {
+			t.Errorf("splitFunction(%q) = %q, %q, want %q, %q", tt.name, pkg, function, tt.pkg, tt.function)
+		}
+	}
+}
+
+func TestModuleRelative(t *testing.T) {
+	tests := []struct {
+		pkg, file, want string
+	}{
+		{"github.com/taigrr/log-socket/v2/log", "/src/log-socket/log/hub.go", "log/hub.go"},
+		{"github.com/taigrr/log-socket/v2", "/src/log-socket/main.go", "main.go"},
+		{"net/http", "/usr/lib/go/src/net/http/server.go", "net/http/server.go"},
+		{"main", "/src/app/cmd/app/main.go", "main.go"},
+	}
+	for _, tt := range tests {
+		if got := moduleRelative(tt.pkg, tt.file); got != tt.want {
+			t.Errorf("moduleRelative(%q, %q) = %q, want %q", tt.pkg, tt.file, got, tt.want)
+		}
+	}
+}
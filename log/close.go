@@ -0,0 +1,122 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrHubClosed is returned by [Close] and [AddSink] once the Hub has been
+// closed.
+var ErrHubClosed = errors.New("hub is closed")
+
+// drainPollInterval is how often Close checks whether clients have read
+// their queued entries.
+const drainPollInterval = 5 * time.Millisecond
+
+// Close shuts down the default Hub. See [Hub.Close].
+func Close(ctx context.Context) (abandoned int, err error) {
+	return defaultHub.Close(ctx)
+}
+
+// Close stops h from accepting entries, then waits until every client has
+// read the entries queued for it and every Sink has written, flushed and
+// closed, or until ctx is done. If ctx ends the wait first, Close returns
+// the number of entries still queued and ctx.Err(); otherwise it returns
+// any errors from flushing and closing the sinks.
+// Clients stay registered, so consumers such as the WebSocket handler can
+// keep reading; clients destroyed while Close waits are not waited for.
+//
+// Entries logged on h after Close are discarded, and Close returns
+// ErrHubClosed if called again. Unlike [Hub.Flush], Close is final: call it
+// once, at the end of the program, after the last entry worth keeping.
+func (h *Hub) Close(ctx context.Context) (abandoned int, err error) {
+	if !h.closed.CompareAndSwap(false, true) {
+		return 0, ErrHubClosed
+	}
+	if d := h.dedup.Load(); d != nil {
+		d.flush()
+	}
+
+	h.sinksMux.Lock()
+	runners := make([]*sinkRunner, 0, len(h.sinks))
+	sinkClients := make(map[*Client]bool, len(h.sinks))
+	for s, r := range h.sinks {
+		runners = append(runners, r)
+		sinkClients[r.client] = true
+		delete(h.sinks, s)
+	}
+	h.sinksMux.Unlock()
+
+	var clients []*Client
+	for _, c := range h.loadClients() {
+		if !sinkClients[c] {
+			clients = append(clients, c)
+		}
+	}
+
+	// Sinks shut down concurrently so one slow Sink cannot use up the
+	// deadline of the others.
+	errs := make([]error, len(runners))
+	var wg sync.WaitGroup
+	for i, r := range runners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = r.shutdown()
+		}()
+	}
+	sinksDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(sinksDone)
+	}()
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for queuedEntries(clients) > 0 && ctx.Err() == nil {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+		}
+	}
+	select {
+	case <-sinksDone:
+	case <-ctx.Done():
+	}
+
+	abandoned = queuedEntries(clients)
+	select {
+	case <-sinksDone:
+	default:
+		// Sinks still shutting down own their errors; only the entries
+		// they have not written are reported.
+		for _, r := range runners {
+			abandoned += len(r.writer)
+		}
+		return abandoned, ctx.Err()
+	}
+	if abandoned > 0 {
+		return abandoned, ctx.Err()
+	}
+	return 0, errors.Join(errs...)
+}
+
+// queuedEntries counts the entries not yet read by the live clients.
+func queuedEntries(clients []*Client) int {
+	n := 0
+	for _, c := range clients {
+		if c.initialized.Load() {
+			n += c.queued()
+		}
+	}
+	return n
+}
+
+// queued returns the number of entries waiting to be read from c.
+func (c *Client) queued() int {
+	c.backlogMux.Lock()
+	defer c.backlogMux.Unlock()
+	return len(c.writer) + len(c.backlog)
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// blockingSink blocks every Write until release is closed.
+type blockingSink struct {
+	memorySink
+	release chan struct{}
+}
+
+func (s *blockingSink) Write(e Entry) error {
+	<-s.release
+	return s.memorySink.Write(e)
+}
+
+func TestCloseDrainsClientsAndSinks(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	s := &memorySink{}
+	h.AddSink(s, LTrace)
+	c := h.CreateClient()
+	l := h.NewLogger("close")
+	for i := 0; i < 50; i++ {
+		l.Info("queued")
+	}
+
+	read := make(chan int)
+	go func() {
+		n := 0
+		for n < 50 {
+			c.Get()
+			n++
+			time.Sleep(time.Millisecond)
+		}
+		read <- n
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	abandoned, err := h.Close(ctx)
+	if abandoned != 0 || err != nil {
+		t.Fatalf("Close = %d, %v, want 0, nil", abandoned, err)
+	}
+	if n := <-read; n != 50 {
+		t.Errorf("client read %d entries, want 50", n)
+	}
+	if got := len(s.outputs()); got != 50 {
+		t.Errorf("sink received %d entries, want 50", got)
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.flushed != 1 || s.closed != 1 {
+		t.Errorf("flushed=%d closed=%d, want 1 and 1", s.flushed, s.closed)
+	}
+}
+
+func TestCloseReportsAbandoned(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	s := &blockingSink{release: make(chan struct{})}
+	defer close(s.release)
+	h.AddSink(s, LTrace)
+	c := h.CreateClient()
+	defer c.Destroy()
+	l := h.NewLogger("close")
+	for i := 0; i < 10; i++ {
+		l.Info("never read")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	abandoned, err := h.Close(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+	// The client holds all ten entries; the sink is stuck writing the
+	// first and still holds the other nine.
+	if abandoned != 19 {
+		t.Errorf("abandoned = %d, want 19", abandoned)
+	}
+}
+
+func TestClosedHubRejectsEntries(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+	if _, err := h.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	h.NewLogger("close").Error("after close")
+	h.Broadcast(Entry{Output: "broadcast", Level: "ERROR"})
+	if _, ok := getEntry(c, 50*time.Millisecond); ok {
+		t.Error("entry delivered after Close")
+	}
+	if _, err := h.Close(context.Background()); !errors.Is(err, ErrHubClosed) {
+		t.Errorf("second Close err = %v, want ErrHubClosed", err)
+	}
+	if err := h.AddSink(&memorySink{}, LTrace); !errors.Is(err, ErrHubClosed) {
+		t.Errorf("AddSink err = %v, want ErrHubClosed", err)
+	}
+}
+
+func TestCloseEmitsDedupSummaries(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetDedupWindow(time.Hour)
+	s := &memorySink{}
+	h.AddSink(s, LTrace)
+	l := h.NewLogger("close")
+	for i := 0; i < 3; i++ {
+		l.Warn("flapping")
+	}
+	if _, err := h.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"flapping", "flapping (repeated 2 times)"}
+	if got := s.outputs(); !slices.Equal(got, want) {
+		t.Errorf("sink received %v, want %v", got, want)
+	}
+}
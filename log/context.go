@@ -0,0 +1,473 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ContextExtractor pulls request-scoped values out of a context and returns
+// them as key/value pairs, in the same form as [Logger.With]. It returns nil
+// when the context carries nothing of interest.
+type ContextExtractor func(ctx context.Context) []any
+
+type namedExtractor struct {
+	name string
+	fn   ContextExtractor
+}
+
+type contextKey int
+
+const (
+	traceparentKey contextKey = iota
+	requestIDKey
+	userIDKey
+)
+
+// ContextWithTraceparent returns a copy of ctx carrying a W3C traceparent
+// header value, e.g. from an incoming request's "traceparent" header.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceparentKey, traceparent)
+}
+
+// ContextWithRequestID returns a copy of ctx carrying a request ID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// ContextWithUserID returns a copy of ctx carrying a user ID.
+func ContextWithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDKey, id)
+}
+
+// TraceparentExtractor adds "trace_id" and "span_id" fields from a valid
+// traceparent set with [ContextWithTraceparent]. It is registered on every
+// Hub as "traceparent".
+func TraceparentExtractor(ctx context.Context) []any {
+	tp, _ := ctx.Value(traceparentKey).(string)
+	traceID, spanID, ok := parseTraceparent(tp)
+	if !ok {
+		return nil
+	}
+	return []any{"trace_id", traceID, "span_id", spanID}
+}
+
+// RequestIDExtractor adds a "request_id" field set with
+// [ContextWithRequestID]. It is registered on every Hub as "request_id".
+func RequestIDExtractor(ctx context.Context) []any {
+	if id, _ := ctx.Value(requestIDKey).(string); id != "" {
+		return []any{"request_id", id}
+	}
+	return nil
+}
+
+// UserIDExtractor adds a "user_id" field set with [ContextWithUserID]. It
+// is registered on every Hub as "user_id".
+func UserIDExtractor(ctx context.Context) []any {
+	if id, _ := ctx.Value(userIDKey).(string); id != "" {
+		return []any{"user_id", id}
+	}
+	return nil
+}
+
+// RegisterContextExtractor adds fn to the default Hub under name. See
+// [Hub.RegisterContextExtractor].
+func RegisterContextExtractor(name string, fn ContextExtractor) {
+	defaultHub.RegisterContextExtractor(name, fn)
+}
+
+// RegisterContextExtractor adds fn to the extractors run for every entry
+// logged on h with a context, such as [Logger.InfoContext]. Registering a
+// name again replaces its extractor in place, so the built-in "traceparent",
+// "request_id" and "user_id" extractors can be swapped for ones that read
+// an application's own context keys. A nil fn removes the extractor.
+// Extractors run in registration order; later fields win on conflicts.
+func (h *Hub) RegisterContextExtractor(name string, fn ContextExtractor) {
+	h.extractorsMux.Lock()
+	defer h.extractorsMux.Unlock()
+	var next []namedExtractor
+	replaced := false
+	for _, x := range h.loadExtractors() {
+		if x.name == name {
+			replaced = true
+			if fn == nil {
+				continue
+			}
+			x.fn = fn
+		}
+		next = append(next, x)
+	}
+	if !replaced && fn != nil {
+		next = append(next, namedExtractor{name: name, fn: fn})
+	}
+	h.extractors.Store(&next)
+}
+
+func (h *Hub) loadExtractors() []namedExtractor {
+	if p := h.extractors.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// contextFields returns fields plus everything the registered extractors
+// find in ctx. fields is never modified.
+func (h *Hub) contextFields(ctx context.Context, fields map[string]any) map[string]any {
+	if ctx == nil {
+		return fields
+	}
+	for _, x := range h.loadExtractors() {
+		fields = mergeFields(fields, x.fn(ctx))
+	}
+	return fields
+}
+
+// parseTraceparent splits a version 00 W3C traceparent header
+// ("00-<trace-id>-<parent-id>-<flags>") into its trace and span IDs.
+func parseTraceparent(tp string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(tp, "-")
+	if len(parts) != 4 || parts[0] != "00" ||
+		!isLowerHex(parts[1], 32) || !isLowerHex(parts[2], 16) || !isLowerHex(parts[3], 2) {
+		return "", "", false
+	}
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// TraceContext is like [Logger.Trace] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) TraceContext(ctx context.Context, args ...any) {
+	if !l.enabled(LTrace) {
+		return
+	}
+	output := fmt.Sprint(args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "TRACE",
+		level:     LTrace,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// TracefContext is like [Logger.Tracef] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) TracefContext(ctx context.Context, format string, args ...any) {
+	if !l.enabled(LTrace) {
+		return
+	}
+	output := fmt.Sprintf(format, args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "TRACE",
+		level:     LTrace,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// DebugContext is like [Logger.Debug] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) DebugContext(ctx context.Context, args ...any) {
+	if !l.enabled(LDebug) {
+		return
+	}
+	output := fmt.Sprint(args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "DEBUG",
+		level:     LDebug,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// DebugfContext is like [Logger.Debugf] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) DebugfContext(ctx context.Context, format string, args ...any) {
+	if !l.enabled(LDebug) {
+		return
+	}
+	output := fmt.Sprintf(format, args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "DEBUG",
+		level:     LDebug,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// InfoContext is like [Logger.Info] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) InfoContext(ctx context.Context, args ...any) {
+	if !l.enabled(LInfo) {
+		return
+	}
+	output := fmt.Sprint(args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "INFO",
+		level:     LInfo,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// InfofContext is like [Logger.Infof] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) InfofContext(ctx context.Context, format string, args ...any) {
+	if !l.enabled(LInfo) {
+		return
+	}
+	output := fmt.Sprintf(format, args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "INFO",
+		level:     LInfo,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// NoticeContext is like [Logger.Notice] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) NoticeContext(ctx context.Context, args ...any) {
+	if !l.enabled(LNotice) {
+		return
+	}
+	output := fmt.Sprint(args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "NOTICE",
+		level:     LNotice,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// NoticefContext is like [Logger.Noticef] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) NoticefContext(ctx context.Context, format string, args ...any) {
+	if !l.enabled(LNotice) {
+		return
+	}
+	output := fmt.Sprintf(format, args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "NOTICE",
+		level:     LNotice,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// WarnContext is like [Logger.Warn] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) WarnContext(ctx context.Context, args ...any) {
+	if !l.enabled(LWarn) {
+		return
+	}
+	output := fmt.Sprint(args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "WARN",
+		level:     LWarn,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// WarnfContext is like [Logger.Warnf] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) WarnfContext(ctx context.Context, format string, args ...any) {
+	if !l.enabled(LWarn) {
+		return
+	}
+	output := fmt.Sprintf(format, args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "WARN",
+		level:     LWarn,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// ErrorContext is like [Logger.Error] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) ErrorContext(ctx context.Context, args ...any) {
+	if !l.enabled(LError) {
+		return
+	}
+	output := fmt.Sprint(args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "ERROR",
+		level:     LError,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// ErrorfContext is like [Logger.Errorf] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) ErrorfContext(ctx context.Context, format string, args ...any) {
+	if !l.enabled(LError) {
+		return
+	}
+	output := fmt.Sprintf(format, args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "ERROR",
+		level:     LError,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
+}
+
+// PanicContext is like [Logger.Panic] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) PanicContext(ctx context.Context, args ...any) {
+	output := fmt.Sprint(args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "PANIC",
+		level:     LPanic,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	if l.enabled(LPanic) {
+		l.setCaller(&e, 2+l.FileInfoDepth)
+		l.getHub().createLog(e)
+	}
+	l.getHub().Flush()
+	if len(args) > 0 {
+		switch args[0].(type) {
+		case error:
+			panic(args[0])
+		default:
+			// falls through to default below
+		}
+	}
+	panic(errors.New(output))
+}
+
+// PanicfContext is like [Logger.Panicf] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) PanicfContext(ctx context.Context, format string, args ...any) {
+	output := fmt.Sprintf(format, args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "PANIC",
+		level:     LPanic,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	if l.enabled(LPanic) {
+		l.setCaller(&e, 2+l.FileInfoDepth)
+		l.getHub().createLog(e)
+	}
+	l.getHub().Flush()
+	if len(args) > 0 {
+		switch args[0].(type) {
+		case error:
+			panic(args[0])
+		default:
+			// falls through to default below
+		}
+	}
+	panic(errors.New(output))
+}
+
+// FatalContext is like [Logger.Fatal] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) FatalContext(ctx context.Context, args ...any) {
+	output := fmt.Sprint(args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "FATAL",
+		level:     LFatal,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	if l.enabled(LFatal) {
+		l.setCaller(&e, 2+l.FileInfoDepth)
+		l.getHub().createLog(e)
+	}
+	l.getHub().exit(1)
+}
+
+// FatalfContext is like [Logger.Fatalf] but also attaches the fields found in ctx
+// by the Hub's context extractors.
+func (l Logger) FatalfContext(ctx context.Context, format string, args ...any) {
+	output := fmt.Sprintf(format, args...)
+	e := Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "FATAL",
+		level:     LFatal,
+		Namespace: l.Namespace,
+		Fields:    l.getHub().contextFields(ctx, l.fields),
+	}
+	if l.enabled(LFatal) {
+		l.setCaller(&e, 2+l.FileInfoDepth)
+		l.getHub().createLog(e)
+	}
+	l.getHub().exit(1)
+}
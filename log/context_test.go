@@ -0,0 +1,149 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		in  string
+		ok  bool
+		tid string
+	}{
+		{testTraceparent, true, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"", false, ""},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, ""},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, ""},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, ""},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, ""},
+		{"00-4bf92f3577b34da6-00f067aa0ba902b7-01", false, ""},
+	}
+	for _, tt := range tests {
+		tid, _, ok := parseTraceparent(tt.in)
+		if ok != tt.ok || tid != tt.tid {
+			t.Errorf("parseTraceparent(%q) = %q, %v; want %q, %v", tt.in, tid, ok, tt.tid, tt.ok)
+		}
+	}
+}
+
+func TestInfoContext(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+
+	ctx := ContextWithTraceparent(context.Background(), testTraceparent)
+	ctx = ContextWithRequestID(ctx, "req-1")
+	ctx = ContextWithUserID(ctx, "user-1")
+	l := h.NewLogger("ctx").With("component", "api")
+	l.InfoContext(ctx, "handled")
+
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	want := map[string]any{
+		"component":  "api",
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":    "00f067aa0ba902b7",
+		"request_id": "req-1",
+		"user_id":    "user-1",
+	}
+	for k, v := range want {
+		if e.Fields[k] != v {
+			t.Errorf("field %s = %v, want %v", k, e.Fields[k], v)
+		}
+	}
+	if len(l.Fields()) != 1 {
+		t.Errorf("logger fields were modified: %v", l.Fields())
+	}
+}
+
+func TestContextWithoutValues(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+	h.NewLogger("ctx").WarnfContext(context.Background(), "n=%d", 1)
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.Output != "n=1" || e.Level != "WARN" {
+		t.Errorf("entry = %q %q", e.Level, e.Output)
+	}
+	if e.Fields != nil {
+		t.Errorf("fields = %v, want none", e.Fields)
+	}
+}
+
+func TestContextBelowLevel(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+	called := false
+	h.RegisterContextExtractor("spy", func(ctx context.Context) []any {
+		called = true
+		return nil
+	})
+	h.NewLogger("ctx", WithLevel(LWarn)).DebugContext(context.Background(), "skipped")
+	if called {
+		t.Error("extractors should not run for disabled levels")
+	}
+	if _, ok := getEntry(c, 50*time.Millisecond); ok {
+		t.Error("disabled level should not be delivered")
+	}
+}
+
+type tenantKey struct{}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+	h.RegisterContextExtractor("tenant", func(ctx context.Context) []any {
+		if v, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []any{"tenant", v}
+		}
+		return nil
+	})
+	// Replace the built-in request ID extractor and remove the user one.
+	h.RegisterContextExtractor("request_id", func(ctx context.Context) []any {
+		return []any{"request_id", "custom"}
+	})
+	h.RegisterContextExtractor("user_id", nil)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	ctx = ContextWithUserID(ctx, "user-1")
+	h.NewLogger("ctx").ErrorContext(ctx, "failed")
+
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.Fields["tenant"] != "acme" || e.Fields["request_id"] != "custom" {
+		t.Errorf("fields = %v", e.Fields)
+	}
+	if _, ok := e.Fields["user_id"]; ok {
+		t.Error("removed extractor still ran")
+	}
+
+	// Other hubs keep the built-in extractors.
+	other := NewHub()
+	oc := other.CreateClient()
+	defer oc.Destroy()
+	other.NewLogger("ctx").InfoContext(ctx, "other")
+	e, ok = getEntry(oc, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.Fields["user_id"] != "user-1" || e.Fields["tenant"] != nil {
+		t.Errorf("fields on other hub = %v", e.Fields)
+	}
+}
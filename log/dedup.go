@@ -0,0 +1,155 @@
+package log
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dedupKey identifies entries that are folded together.
+type dedupKey struct {
+	namespace string
+	level     Level
+	output    string
+	file      string
+}
+
+// dedupState tracks repeats of one entry within its window.
+type dedupState struct {
+	last    Entry
+	repeats int
+	expires time.Time
+}
+
+// maxDedupPending caps the number of open windows. Once it is reached, new
+// distinct entries are delivered without being tracked, so high-cardinality
+// output cannot grow the table without bound.
+const maxDedupPending = 4096
+
+// deduper suppresses identical entries within a window. The first entry is
+// passed through immediately; repeats are counted and reported by a single
+// summary entry when the window closes. Windows are closed by one sweeping
+// goroutine that runs only while any are open.
+type deduper struct {
+	mux      sync.Mutex
+	hub      *Hub
+	window   time.Duration
+	pending  map[dedupKey]*dedupState
+	sweeping bool
+}
+
+// SetDedupWindow enables duplicate suppression on the default Hub. See
+// [Hub.SetDedupWindow].
+func SetDedupWindow(window time.Duration) {
+	defaultHub.SetDedupWindow(window)
+}
+
+// SetDedupWindow folds entries with the same namespace, level, output and
+// file logged within window of the first one. The first entry is delivered
+// as usual; when the window closes, any repeats are reported by one summary
+// entry whose output ends in "(repeated N times)" and which carries a
+// "repeated" field. Panic and Fatal entries are never folded. At most 4096
+// distinct entries are tracked at once; further ones are delivered without
+// folding. A zero window disables suppression, emitting any pending
+// summaries first.
+func (h *Hub) SetDedupWindow(window time.Duration) {
+	var next *deduper
+	if window > 0 {
+		next = &deduper{hub: h, window: window, pending: make(map[dedupKey]*dedupState)}
+	}
+	if prev := h.dedup.Swap(next); prev != nil {
+		prev.flush()
+	}
+}
+
+// admit reports whether e should be delivered, counting it as a repeat
+// otherwise.
+func (d *deduper) admit(e Entry) bool {
+	if e.level >= LPanic {
+		return true
+	}
+	key := dedupKey{namespace: e.Namespace, level: e.level, output: e.Output, file: e.File}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if st, ok := d.pending[key]; ok {
+		st.last = e
+		st.repeats++
+		return false
+	}
+	if len(d.pending) >= maxDedupPending {
+		return true
+	}
+	d.pending[key] = &dedupState{last: e, expires: time.Now().Add(d.window)}
+	if !d.sweeping {
+		d.sweeping = true
+		go d.sweep()
+	}
+	return true
+}
+
+// sweep closes expired windows until none are left open.
+func (d *deduper) sweep() {
+	ticker := time.NewTicker(max(d.window/10, 10*time.Millisecond))
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if !d.expire(now) {
+			return
+		}
+	}
+}
+
+// expire emits a summary for every window closed by now and reports
+// whether any remain open. When none do, the sweeper stops.
+func (d *deduper) expire(now time.Time) bool {
+	d.mux.Lock()
+	var closed []*dedupState
+	for key, st := range d.pending {
+		if !now.Before(st.expires) {
+			closed = append(closed, st)
+			delete(d.pending, key)
+		}
+	}
+	more := len(d.pending) > 0
+	d.sweeping = more
+	d.mux.Unlock()
+	d.emitAll(closed)
+	return more
+}
+
+// flush closes every open window immediately.
+func (d *deduper) flush() {
+	d.mux.Lock()
+	states := make([]*dedupState, 0, len(d.pending))
+	for key, st := range d.pending {
+		states = append(states, st)
+		delete(d.pending, key)
+	}
+	d.mux.Unlock()
+	d.emitAll(states)
+}
+
+// emitAll emits the summaries of states in the order their windows opened.
+func (d *deduper) emitAll(states []*dedupState) {
+	slices.SortFunc(states, func(a, b *dedupState) int {
+		return a.expires.Compare(b.expires)
+	})
+	for _, st := range states {
+		d.emit(st)
+	}
+}
+
+func (d *deduper) emit(st *dedupState) {
+	if st.repeats == 0 {
+		return
+	}
+	e := st.last
+	times := "times"
+	if st.repeats == 1 {
+		times = "time"
+	}
+	e.Output = fmt.Sprintf("%s (repeated %d %s)", strings.TrimSuffix(e.Output, "\n"), st.repeats, times)
+	e.Fields = mergeFields(e.Fields, []any{"repeated", st.repeats})
+	d.hub.publish(e)
+}
@@ -0,0 +1,165 @@
+package log
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetDedupWindow(50 * time.Millisecond)
+	c := h.CreateClient()
+	defer c.Destroy()
+
+	l := h.NewLogger("dedup")
+	for i := 0; i < 100; i++ {
+		l.Warn("Slow query detected")
+	}
+	l.Info("different")
+
+	e, ok := getEntry(c, time.Second)
+	if !ok || e.Output != "Slow query detected" {
+		t.Fatalf("first entry = %q, %v", e.Output, ok)
+	}
+	e, ok = getEntry(c, time.Second)
+	if !ok || e.Output != "different" {
+		t.Fatalf("second entry = %q, %v", e.Output, ok)
+	}
+	e, ok = getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out waiting for summary")
+	}
+	if e.Output != "Slow query detected (repeated 99 times)" || e.Level != "WARN" {
+		t.Errorf("summary = %s %q", e.Level, e.Output)
+	}
+	if e.Fields["repeated"] != 99 {
+		t.Errorf("repeated field = %v, want 99", e.Fields["repeated"])
+	}
+
+	// A new window starts once the previous one closed.
+	l.Warn("Slow query detected")
+	if e, ok := getEntry(c, time.Second); !ok || e.Output != "Slow query detected" {
+		t.Errorf("entry after window = %q, %v", e.Output, ok)
+	}
+}
+
+func TestDedupNoRepeats(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetDedupWindow(20 * time.Millisecond)
+	c := h.CreateClient()
+	defer c.Destroy()
+	h.NewLogger("dedup").Info("once")
+	getEntry(c, time.Second)
+	if e, ok := getEntry(c, 100*time.Millisecond); ok {
+		t.Errorf("unexpected summary %q", e.Output)
+	}
+}
+
+func TestDedupPanicNotFolded(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetDedupWindow(time.Hour)
+	c := h.CreateClient()
+	defer c.Destroy()
+	for i := 0; i < 3; i++ {
+		h.Broadcast(Entry{Output: "boom", Level: "PANIC", Namespace: "dedup"})
+	}
+	for i := 0; i < 3; i++ {
+		if _, ok := getEntry(c, time.Second); !ok {
+			t.Fatalf("panic entry %d was folded", i)
+		}
+	}
+}
+
+func TestDedupFlush(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetDedupWindow(time.Hour)
+	s := &memorySink{}
+	h.AddSink(s, LTrace)
+	l := h.NewLogger("dedup")
+	for i := 0; i < 3; i++ {
+		l.Error("down")
+	}
+	h.Flush()
+	want := []string{"down", "down (repeated 2 times)"}
+	if got := s.outputs(); !slices.Equal(got, want) {
+		t.Errorf("sink received %v, want %v", got, want)
+	}
+}
+
+func TestDedupDisable(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetDedupWindow(time.Hour)
+	c := h.CreateClient()
+	defer c.Destroy()
+	l := h.NewLogger("dedup")
+	tick := func() { l.Info("tick") }
+	tick()
+	tick()
+	h.SetDedupWindow(0)
+	tick()
+	var got []string
+	for i := 0; i < 3; i++ {
+		e, ok := getEntry(c, time.Second)
+		if !ok {
+			t.Fatal("timed out")
+		}
+		got = append(got, e.Output)
+	}
+	if want := []string{"tick", "tick (repeated 1 time)", "tick"}; !slices.Equal(got, want) {
+		t.Errorf("received %v, want %v", got, want)
+	}
+}
+
+func TestDedupPendingCapped(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetDedupWindow(time.Hour)
+	d := h.dedup.Load()
+	for i := 0; i < maxDedupPending; i++ {
+		if !d.admit(Entry{Output: strconv.Itoa(i), level: LInfo}) {
+			t.Fatalf("distinct entry %d was folded", i)
+		}
+	}
+	// Beyond the cap, new entries pass through untracked.
+	overflow := Entry{Output: "overflow", level: LInfo}
+	if !d.admit(overflow) || !d.admit(overflow) {
+		t.Error("entry beyond the cap was folded")
+	}
+	d.mux.Lock()
+	n := len(d.pending)
+	d.mux.Unlock()
+	if n != maxDedupPending {
+		t.Errorf("pending = %d, want %d", n, maxDedupPending)
+	}
+	h.SetDedupWindow(0)
+}
+
+func TestDedupSweeperStops(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetDedupWindow(20 * time.Millisecond)
+	d := h.dedup.Load()
+	for i := 0; i < 100; i++ {
+		d.admit(Entry{Output: strconv.Itoa(i), level: LInfo})
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		d.mux.Lock()
+		sweeping, n := d.sweeping, len(d.pending)
+		d.mux.Unlock()
+		if !sweeping && n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("sweeping=%v pending=%d after the windows closed", sweeping, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// badKey is used for a value that has no matching key, mirroring log/slog.
+const badKey = "!BADKEY"
+
+// mergeFields returns a new map holding parent plus the key/value pairs in
+// kv. Keys that are not strings are converted with fmt.Sprint. The parent
+// map is never modified, so it can be shared between loggers and entries.
+func mergeFields(parent map[string]any, kv []any) map[string]any {
+	if len(kv) == 0 {
+		return parent
+	}
+	fields := make(map[string]any, len(parent)+(len(kv)+1)/2)
+	maps.Copy(fields, parent)
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 >= len(kv) {
+			fields[badKey] = kv[i]
+			break
+		}
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		fields[key] = kv[i+1]
+	}
+	return fields
+}
+
+// formatFields renders fields as space-separated key=value pairs sorted by
+// key. Values containing spaces, quotes or '=' are quoted.
+func formatFields(fields map[string]any) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i, k := range slices.Sorted(maps.Keys(fields)) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(quoteIfNeeded(fmt.Sprint(fields[k])))
+	}
+	return b.String()
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// normalizeFields returns fields with every value made safe to encode as
+// JSON: errors become their message, and values json.Marshal rejects, such
+// as NaN, channels and functions, become their fmt.Sprint form. The map is
+// copied before the first change, since it may be shared.
+func normalizeFields(fields map[string]any) map[string]any {
+	var out map[string]any
+	for k, v := range fields {
+		nv, changed := jsonValue(v)
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = maps.Clone(fields)
+		}
+		out[k] = nv
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// jsonValue returns v's JSON-safe replacement and whether it differs from v.
+func jsonValue(v any) (any, bool) {
+	switch v := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, uintptr:
+		return v, false
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Sprint(v), true
+		}
+		return v, false
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return fmt.Sprint(v), true
+		}
+		return v, false
+	case error:
+		// Most errors marshal as {}, hiding the message.
+		return v.Error(), true
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprint(v), true
+	}
+	return v, false
+}
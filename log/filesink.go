@@ -0,0 +1,288 @@
+package log
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeLayout names rotated segments so they sort chronologically.
+const backupTimeLayout = "2006-01-02T15-04-05.000000000"
+
+// FileSinkOptions configures a [FileSink]. The zero value writes plain text
+// to a single file that is never rotated.
+type FileSinkOptions struct {
+	// Formatter renders entries. Defaults to a [TextFormatter] without
+	// color; use [JSONFormatter] for NDJSON.
+	Formatter Formatter
+	// MaxSize rotates the file before a write would grow it beyond this
+	// many bytes. Zero disables size-based rotation.
+	MaxSize int64
+	// RotateEvery rotates the file once it has been open this long. Zero
+	// disables time-based rotation.
+	RotateEvery time.Duration
+	// Compress gzips rotated segments.
+	Compress bool
+	// MaxAge removes rotated segments older than this. Zero keeps them
+	// regardless of age.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated segments to keep. Zero keeps
+	// them all.
+	MaxBackups int
+}
+
+// FileSink is a [Sink] that writes entries to a file, rotating it by size
+// and age. Rotated segments are renamed with a timestamp, e.g.
+// app-2024-11-10T15-42-49.777298000.log, optionally gzipped, and pruned
+// in the background. Register it with [AddSink]; [Flush] writes out
+// anything still buffered, so entries logged before [Fatal] reach the file.
+type FileSink struct {
+	mux    sync.Mutex
+	path   string
+	opts   FileSinkOptions
+	file   *os.File
+	w      *bufio.Writer
+	size   int64
+	opened time.Time
+	now    func() time.Time
+	// closed is set by Close. A nil file with closed unset means a
+	// failed rotation, and the next Write reopens the file.
+	closed bool
+
+	// millMux serializes compression and pruning of rotated segments.
+	millMux sync.Mutex
+	mills   sync.WaitGroup
+}
+
+// NewFileSink opens path for appending, creating it and its directory if
+// needed, and returns a FileSink that writes to it.
+func NewFileSink(path string, opts FileSinkOptions) (*FileSink, error) {
+	if opts.Formatter == nil {
+		opts.Formatter = &TextFormatter{NoColor: true}
+	}
+	s := &FileSink{path: path, opts: opts, now: time.Now}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write formats e and appends it to the file, rotating first if needed. If
+// rotation fails, e is still appended to the current file and the rotation
+// error is returned.
+func (s *FileSink) Write(e Entry) error {
+	b, err := s.opts.Formatter.Format(e)
+	if err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if err := s.reopen(); err != nil {
+		return err
+	}
+	var rotateErr error
+	if s.shouldRotate(len(b)) {
+		rotateErr = s.rotate()
+		if s.file == nil {
+			return rotateErr
+		}
+	}
+	n, err := s.w.Write(b)
+	s.size += int64(n)
+	return errors.Join(rotateErr, err)
+}
+
+// Flush writes any buffered output to the file.
+func (s *FileSink) Flush() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.w.Flush()
+}
+
+// Rotate closes the current file, moves it aside and opens a new one.
+func (s *FileSink) Rotate() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if err := s.reopen(); err != nil {
+		return err
+	}
+	return s.rotate()
+}
+
+// Close flushes and closes the file, then waits for any pending
+// compression and pruning to finish.
+func (s *FileSink) Close() error {
+	s.mux.Lock()
+	s.closed = true
+	err := s.closeFile()
+	s.mux.Unlock()
+	s.mills.Wait()
+	return err
+}
+
+func (s *FileSink) shouldRotate(n int) bool {
+	if s.size == 0 {
+		return false
+	}
+	if s.opts.MaxSize > 0 && s.size+int64(n) > s.opts.MaxSize {
+		return true
+	}
+	return s.opts.RotateEvery > 0 && s.now().Sub(s.opened) >= s.opts.RotateEvery
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.file = f
+	s.w = bufio.NewWriter(f)
+	s.size = info.Size()
+	s.opened = s.now()
+	return nil
+}
+
+// reopen opens the file again after a failed rotation left it closed.
+func (s *FileSink) reopen() error {
+	if s.closed {
+		return os.ErrClosed
+	}
+	if s.file != nil {
+		return nil
+	}
+	return s.open()
+}
+
+func (s *FileSink) closeFile() error {
+	if s.file == nil {
+		return nil
+	}
+	err := errors.Join(s.w.Flush(), s.file.Sync(), s.file.Close())
+	s.file = nil
+	s.w = nil
+	return err
+}
+
+// rotate moves the current file aside and opens a new one. If closing or
+// renaming fails, it reopens the current file for appending so the sink
+// keeps working.
+func (s *FileSink) rotate() error {
+	if err := s.closeFile(); err != nil {
+		return errors.Join(err, s.open())
+	}
+	base, ext := s.nameParts()
+	backup := filepath.Join(filepath.Dir(s.path), base+"-"+s.now().Format(backupTimeLayout)+ext)
+	if err := os.Rename(s.path, backup); err != nil {
+		return errors.Join(err, s.open())
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	s.mills.Add(1)
+	go s.mill(backup)
+	return nil
+}
+
+// mill compresses a freshly rotated segment and prunes old ones.
+func (s *FileSink) mill(backup string) {
+	defer s.mills.Done()
+	s.millMux.Lock()
+	defer s.millMux.Unlock()
+	if s.opts.Compress {
+		compressFile(backup)
+	}
+	s.prune()
+}
+
+func (s *FileSink) prune() {
+	if s.opts.MaxAge <= 0 && s.opts.MaxBackups <= 0 {
+		return
+	}
+	backups := s.backups()
+	// Newest first.
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].t.After(backups[j].t)
+	})
+	cutoff := s.now().Add(-s.opts.MaxAge)
+	for i, b := range backups {
+		if (s.opts.MaxBackups > 0 && i >= s.opts.MaxBackups) ||
+			(s.opts.MaxAge > 0 && b.t.Before(cutoff)) {
+			os.Remove(b.path)
+		}
+	}
+}
+
+type backupFile struct {
+	path string
+	t    time.Time
+}
+
+// backups lists the rotated segments of s.path with their rotation times.
+func (s *FileSink) backups() []backupFile {
+	dir := filepath.Dir(s.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	base, ext := s.nameParts()
+	var backups []backupFile
+	for _, de := range entries {
+		name := strings.TrimSuffix(de.Name(), ".gz")
+		if de.IsDir() || !strings.HasPrefix(name, base+"-") || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, base+"-"), ext)
+		t, err := time.ParseInLocation(backupTimeLayout, ts, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, de.Name()), t: t})
+	}
+	return backups
+}
+
+// nameParts splits the file name into its base and extension, e.g. "app"
+// and ".log".
+func (s *FileSink) nameParts() (base, ext string) {
+	name := filepath.Base(s.path)
+	ext = filepath.Ext(name)
+	return strings.TrimSuffix(name, ext), ext
+}
+
+// compressFile replaces path with a gzipped copy at path.gz.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err = errors.Join(err, zw.Close(), dst.Close()); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,254 @@
+package log
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fileEntry(output string) Entry {
+	return Entry{
+		Timestamp: time.Now(),
+		Output:    output,
+		Level:     "INFO",
+		Namespace: "file",
+		level:     LInfo,
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// rotatedFiles returns the names of the rotated segments in dir.
+func rotatedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, de := range entries {
+		if de.Name() != "app.log" {
+			names = append(names, de.Name())
+		}
+	}
+	return names
+}
+
+func TestFileSinkWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	s, err := NewFileSink(path, FileSinkOptions{Formatter: &JSONFormatter{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Write(fileEntry("hello"))
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	var e Entry
+	if err := json.Unmarshal([]byte(readFile(t, path)), &e); err != nil {
+		t.Fatalf("file is not NDJSON: %v", err)
+	}
+	if e.Output != "hello" {
+		t.Errorf("output = %q, want hello", e.Output)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(fileEntry("late")); err == nil {
+		t.Error("Write after Close should fail")
+	}
+}
+
+func TestFileSinkAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	for _, msg := range []string{"first", "second"} {
+		s, err := NewFileSink(path, FileSinkOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Write(fileEntry(msg))
+		s.Close()
+	}
+	got := readFile(t, path)
+	if !strings.Contains(got, "first") || !strings.Contains(got, "second") {
+		t.Errorf("file = %q, want both entries", got)
+	}
+	if strings.Contains(got, "\033[") {
+		t.Error("file output should not be colorized")
+	}
+}
+
+func TestFileSinkRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	s, err := NewFileSink(path, FileSinkOptions{MaxSize: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		s.Write(fileEntry(strings.Repeat("x", 50)))
+	}
+	s.Close()
+	if n := len(rotatedFiles(t, dir)); n < 2 {
+		t.Errorf("got %d rotated files, want at least 2", n)
+	}
+	if size := len(readFile(t, path)); size > 150 {
+		t.Errorf("active file is %d bytes, want at most 150", size)
+	}
+}
+
+func TestFileSinkRotateByTime(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	s, err := NewFileSink(filepath.Join(dir, "app.log"), FileSinkOptions{RotateEvery: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return now }
+	s.opened = now
+	s.Write(fileEntry("one"))
+	s.Write(fileEntry("two"))
+	if n := len(rotatedFiles(t, dir)); n != 0 {
+		t.Errorf("rotated %d files before the interval elapsed", n)
+	}
+	now = now.Add(time.Hour)
+	s.Write(fileEntry("three"))
+	s.Close()
+	if n := len(rotatedFiles(t, dir)); n != 1 {
+		t.Errorf("got %d rotated files, want 1", n)
+	}
+}
+
+func TestFileSinkRotateFailureRecovers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	now := time.Now()
+	s, err := NewFileSink(path, FileSinkOptions{MaxSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.now = func() time.Time { return now }
+	// A non-empty directory at the backup name makes the rename fail.
+	backup := filepath.Join(dir, "app-"+now.Format(backupTimeLayout)+".log")
+	if err := os.MkdirAll(filepath.Join(backup, "block"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Write(fileEntry(strings.Repeat("x", 60)))
+	if err := s.Write(fileEntry("kept despite rename failure")); err == nil {
+		t.Error("Write returned no error for a failed rotation")
+	}
+	s.Flush()
+	if got := readFile(t, path); !strings.Contains(got, "kept despite rename failure") {
+		t.Errorf("file = %q, want the entry that triggered the rotation", got)
+	}
+
+	if err := os.RemoveAll(backup); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(fileEntry("after recovery")); err != nil {
+		t.Fatalf("Write after the collision cleared: %v", err)
+	}
+	s.Flush()
+	if got := readFile(t, path); !strings.Contains(got, "after recovery") {
+		t.Errorf("file = %q, want the entry written after recovery", got)
+	}
+	if n := len(rotatedFiles(t, dir)); n != 1 {
+		t.Errorf("got %d rotated files, want 1", n)
+	}
+}
+
+func TestFileSinkCompress(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileSink(filepath.Join(dir, "app.log"), FileSinkOptions{Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Write(fileEntry("compressed"))
+	if err := s.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	names := rotatedFiles(t, dir)
+	if len(names) != 1 || !strings.HasSuffix(names[0], ".log.gz") {
+		t.Fatalf("rotated files = %v, want one .log.gz", names)
+	}
+	f, err := os.Open(filepath.Join(dir, names[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(zr)
+	if !strings.Contains(string(b), "compressed") {
+		t.Errorf("decompressed segment = %q", b)
+	}
+}
+
+func TestFileSinkMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileSink(filepath.Join(dir, "app.log"), FileSinkOptions{MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		s.Write(fileEntry("x"))
+		s.Rotate()
+	}
+	s.Close()
+	if n := len(rotatedFiles(t, dir)); n != 2 {
+		t.Errorf("kept %d rotated files, want 2", n)
+	}
+}
+
+func TestFileSinkMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	s, err := NewFileSink(filepath.Join(dir, "app.log"), FileSinkOptions{MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return now }
+	s.Write(fileEntry("old"))
+	s.Rotate()
+	s.mills.Wait()
+	now = now.Add(48 * time.Hour)
+	s.Write(fileEntry("new"))
+	s.Rotate()
+	s.Close()
+	if n := len(rotatedFiles(t, dir)); n != 1 {
+		t.Errorf("kept %d rotated files, want 1", n)
+	}
+}
+
+func TestFileSinkFlushedByHub(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "app.log")
+	s, err := NewFileSink(path, FileSinkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHub()
+	h.AddSink(s, LTrace)
+	h.NewLogger("file").Error("last words")
+	h.Flush()
+	if got := readFile(t, path); !strings.Contains(got, "last words") {
+		t.Errorf("file = %q, want the entry written before Flush", got)
+	}
+}
@@ -0,0 +1,206 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Formatter renders an [Entry] as a single line of output, including the
+// trailing newline.
+type Formatter interface {
+	Format(e Entry) ([]byte, error)
+}
+
+// TextFormatter renders entries as tab-separated columns: timestamp, level,
+// [namespace], output followed by any fields, and file. Level, namespace and
+// file are colorized when color is enabled (see [SetColorEnabled]).
+type TextFormatter struct {
+	// TimeLayout is the layout passed to [time.Time.Format]. If empty,
+	// [time.Time.String] is used.
+	TimeLayout string
+	// UTC converts timestamps to UTC before formatting.
+	UTC bool
+	// NoColor disables ANSI colors regardless of [ColorEnabled].
+	NoColor bool
+}
+
+// Format implements [Formatter].
+func (f *TextFormatter) Format(e Entry) ([]byte, error) {
+	var ts string
+	if f.TimeLayout == "" {
+		ts = entryTime(e, f.UTC).String()
+	} else {
+		ts = entryTime(e, f.UTC).Format(f.TimeLayout)
+	}
+	levelStr := e.Level
+	nsStr := "[" + e.Namespace + "]"
+	fieldsStr := formatFields(e.Fields)
+	fileStr := e.File
+	if !f.NoColor {
+		levelStr = colorizeLevelText(e.Level, e.level)
+		nsStr = colorize(nsStr, colorPurple)
+		if fieldsStr != "" {
+			fieldsStr = colorize(fieldsStr, colorCyan)
+		}
+		fileStr = colorize(fileStr, colorGray)
+	}
+	output := e.Output
+	if fieldsStr != "" {
+		output = strings.TrimSuffix(output, "\n") + " " + fieldsStr
+	}
+	line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n", ts, levelStr, nsStr, output, fileStr)
+	if len(e.Stack) > 0 {
+		stack := formatStack(e.Stack)
+		if !f.NoColor {
+			stack = colorize(stack, colorGray)
+		}
+		line += stack
+	}
+	return []byte(line), nil
+}
+
+// formatStack renders frames like a Go panic trace: each function on its
+// own indented line, followed by its file and line.
+func formatStack(stack []Frame) string {
+	var b strings.Builder
+	for _, f := range stack {
+		fmt.Fprintf(&b, "\t%s\n\t\t%s:%d\n", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+// JSONFormatter renders entries as newline-delimited JSON using the same
+// schema as the WebSocket stream.
+type JSONFormatter struct {
+	// TimeLayout, if set, formats the timestamp as a string with this
+	// layout instead of RFC 3339 with nanoseconds.
+	TimeLayout string
+	// UTC converts timestamps to UTC before formatting.
+	UTC bool
+}
+
+// Format implements [Formatter].
+func (f *JSONFormatter) Format(e Entry) ([]byte, error) {
+	e.Timestamp = entryTime(e, f.UTC)
+	e.Fields = normalizeFields(e.Fields)
+	var (
+		b   []byte
+		err error
+	)
+	if f.TimeLayout == "" {
+		b, err = json.Marshal(e)
+	} else {
+		b, err = json.Marshal(struct {
+			Entry
+			Timestamp string `json:"timestamp"`
+		}{e, e.Timestamp.Format(f.TimeLayout)})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// LogfmtFormatter renders entries as logfmt key=value pairs: time, level,
+// namespace, msg and file, followed by the entry's fields sorted by key.
+type LogfmtFormatter struct {
+	// TimeLayout is the layout passed to [time.Time.Format]. If empty,
+	// [time.RFC3339Nano] is used.
+	TimeLayout string
+	// UTC converts timestamps to UTC before formatting.
+	UTC bool
+}
+
+// Format implements [Formatter].
+func (f *LogfmtFormatter) Format(e Entry) ([]byte, error) {
+	layout := f.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	var b strings.Builder
+	writePair := func(key, value string) {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(quoteIfNeeded(value))
+	}
+	writePair("time", entryTime(e, f.UTC).Format(layout))
+	writePair("level", e.Level)
+	writePair("namespace", e.Namespace)
+	writePair("msg", strings.TrimSuffix(e.Output, "\n"))
+	writePair("file", e.File)
+	for _, k := range slices.Sorted(maps.Keys(e.Fields)) {
+		writePair(k, fmt.Sprint(e.Fields[k]))
+	}
+	b.WriteByte('\n')
+	return []byte(b.String()), nil
+}
+
+// TemplateFormatter renders entries with a [text/template]. The template is
+// executed with a value exposing every [Entry] field plus Time, the
+// timestamp formatted with TimeLayout. The function "fields" renders a
+// fields map as key=value pairs. A trailing newline is added if the
+// template output lacks one. Create one with [NewTemplateFormatter]; the
+// zero value has no template and fails to format.
+type TemplateFormatter struct {
+	// TimeLayout is the layout used for .Time. If empty, [time.RFC3339]
+	// is used.
+	TimeLayout string
+	// UTC converts timestamps to UTC before formatting.
+	UTC bool
+
+	tmpl *template.Template
+}
+
+// NewTemplateFormatter parses text and returns a TemplateFormatter, e.g.
+//
+//	NewTemplateFormatter("{{.Time}} {{.Level}} {{.Output}} {{fields .Fields}}")
+func NewTemplateFormatter(text string) (*TemplateFormatter, error) {
+	tmpl, err := template.New("entry").Funcs(template.FuncMap{
+		"fields": formatFields,
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+// Format implements [Formatter].
+func (f *TemplateFormatter) Format(e Entry) ([]byte, error) {
+	if f.tmpl == nil {
+		return nil, errors.New("template formatter has no template, use NewTemplateFormatter")
+	}
+	layout := f.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	e.Timestamp = entryTime(e, f.UTC)
+	data := struct {
+		Entry
+		Time string
+	}{e, e.Timestamp.Format(layout)}
+	var buf bytes.Buffer
+	if err := f.tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+func entryTime(e Entry, utc bool) time.Time {
+	if utc {
+		return e.Timestamp.UTC()
+	}
+	return e.Timestamp
+}
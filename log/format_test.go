@@ -0,0 +1,151 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEntry() Entry {
+	return Entry{
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("EST", -5*3600)),
+		Output:    "request done",
+		File:      "main.go:42",
+		Level:     "INFO",
+		Namespace: "api",
+		Fields:    map[string]any{"status": 200, "path": "/a b"},
+		level:     LInfo,
+	}
+}
+
+func TestTextFormatter(t *testing.T) {
+	f := &TextFormatter{TimeLayout: time.RFC3339, UTC: true, NoColor: true}
+	b, err := f.Format(testEntry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "2024-01-02T08:04:05Z\tINFO\t[api]\trequest done path=\"/a b\" status=200\tmain.go:42\n"
+	if string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+
+	// Output from the ln variants ends in a newline that must not split
+	// the line before the fields.
+	e := testEntry()
+	e.Output += "\n"
+	b, err = f.Format(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want {
+		t.Errorf("ln output: got %q, want %q", b, want)
+	}
+}
+
+func TestJSONFormatter(t *testing.T) {
+	e := testEntry()
+	b, err := (&JSONFormatter{}).Format(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(b, []byte("\n")) {
+		t.Error("JSON output should end with a newline")
+	}
+	// The schema must match the WebSocket payload.
+	wsPayload, _ := json.Marshal(e)
+	if string(bytes.TrimSuffix(b, []byte("\n"))) != string(wsPayload) {
+		t.Errorf("got %s, want %s", b, wsPayload)
+	}
+
+	b, err = (&JSONFormatter{TimeLayout: time.DateTime, UTC: true}).Format(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["timestamp"] != "2024-01-02 08:04:05" {
+		t.Errorf("timestamp = %v, want %q", decoded["timestamp"], "2024-01-02 08:04:05")
+	}
+	if decoded["namespace"] != "api" {
+		t.Errorf("namespace = %v, want api", decoded["namespace"])
+	}
+}
+
+func TestJSONFormatterUnsupportedFields(t *testing.T) {
+	e := testEntry()
+	e.Fields = map[string]any{
+		"ratio": math.NaN(),
+		"ch":    make(chan int),
+		"err":   errors.New("connection refused"),
+		"count": 3,
+	}
+	b, err := (&JSONFormatter{}).Format(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Entry
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Fields["ratio"] != "NaN" || decoded.Fields["err"] != "connection refused" ||
+		decoded.Fields["count"] != 3.0 {
+		t.Errorf("fields = %v", decoded.Fields)
+	}
+	if s, ok := decoded.Fields["ch"].(string); !ok || !strings.HasPrefix(s, "0x") {
+		t.Errorf("ch = %#v, want its fmt.Sprint form", decoded.Fields["ch"])
+	}
+	if _, ok := e.Fields["err"].(error); !ok {
+		t.Error("Format modified the entry's fields")
+	}
+}
+
+func TestLogfmtFormatter(t *testing.T) {
+	b, err := (&LogfmtFormatter{UTC: true}).Format(testEntry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `time=2024-01-02T08:04:05Z level=INFO namespace=api msg="request done" file=main.go:42 path="/a b" status=200` + "\n"
+	if string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+}
+
+func TestTemplateFormatter(t *testing.T) {
+	f, err := NewTemplateFormatter("{{.Time}} {{.Level}} {{.Namespace}}: {{.Output}} {{fields .Fields}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.TimeLayout = time.TimeOnly
+	f.UTC = true
+	b, err := f.Format(testEntry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `08:04:05 INFO api: request done path="/a b" status=200` + "\n"
+	if string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+
+	if _, err := NewTemplateFormatter("{{.Broken"); err == nil {
+		t.Error("expected parse error for invalid template")
+	}
+	if _, err := (&TemplateFormatter{UTC: true}).Format(testEntry()); err == nil {
+		t.Error("expected error from a TemplateFormatter without a template")
+	}
+}
+
+func TestWriterSinkSetFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewWriterSink(&buf)
+	s.SetFormatter(&LogfmtFormatter{TimeLayout: "x"})
+	s.Write(testEntry())
+	if !bytes.HasPrefix(buf.Bytes(), []byte("time=x level=INFO")) {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
@@ -0,0 +1,159 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// entryOverhead approximates the fixed per-entry cost counted against the
+// history byte limit: the timestamp, level and bookkeeping.
+const entryOverhead = 64
+
+// historyBuffer is a bounded FIFO of recent entries. Entries are appended
+// in sequence order while mux is held, which lets new subscribers snapshot
+// the buffer and start live delivery without gaps or duplicates.
+type historyBuffer struct {
+	mux        sync.Mutex
+	maxEntries int
+	maxBytes   int
+	entries    []Entry
+	sizes      []int
+	head       int
+	bytes      int
+}
+
+// SetHistoryLimits enables the default Hub's history buffer. See
+// [Hub.SetHistoryLimits].
+func SetHistoryLimits(maxEntries, maxBytes int) {
+	defaultHub.SetHistoryLimits(maxEntries, maxBytes)
+}
+
+// History returns the entries currently held in the default Hub's history
+// buffer, oldest first.
+func History() []Entry {
+	return defaultHub.History()
+}
+
+// SetHistoryLimits enables an in-memory buffer of recent entries that new
+// clients can replay (see [ClientOptions.ReplayLast],
+// [ClientOptions.ReplaySince] and [ClientOptions.ReplayAfter]). The oldest
+// entries are evicted once the buffer holds more than maxEntries entries or
+// more than roughly maxBytes bytes. The byte count covers each entry's
+// output, file, function, package, namespace, fields and stack frames. A
+// zero limit is unbounded; passing zero for both disables the history and
+// discards its contents.
+func (h *Hub) SetHistoryLimits(maxEntries, maxBytes int) {
+	if maxEntries <= 0 && maxBytes <= 0 {
+		h.history.Store(nil)
+		return
+	}
+	hist := h.history.Load()
+	if hist == nil {
+		h.history.CompareAndSwap(nil, &historyBuffer{})
+		hist = h.history.Load()
+	}
+	hist.mux.Lock()
+	hist.maxEntries = max(maxEntries, 0)
+	hist.maxBytes = max(maxBytes, 0)
+	hist.evict()
+	hist.mux.Unlock()
+}
+
+// History returns the entries currently held in h's history buffer, oldest
+// first. It returns nil when history is disabled.
+func (h *Hub) History() []Entry {
+	hist := h.history.Load()
+	if hist == nil {
+		return nil
+	}
+	hist.mux.Lock()
+	defer hist.mux.Unlock()
+	return append([]Entry(nil), hist.entries[hist.head:]...)
+}
+
+func (b *historyBuffer) push(e Entry) {
+	size := entrySize(e)
+	b.entries = append(b.entries, e)
+	b.sizes = append(b.sizes, size)
+	b.bytes += size
+	b.evict()
+}
+
+// evict drops the oldest entries until the buffer is within its limits.
+func (b *historyBuffer) evict() {
+	for b.len() > 0 &&
+		((b.maxEntries > 0 && b.len() > b.maxEntries) || (b.maxBytes > 0 && b.bytes > b.maxBytes)) {
+		b.bytes -= b.sizes[b.head]
+		b.entries[b.head] = Entry{}
+		b.head++
+	}
+	// Compact once more than half of the backing array is evicted slots.
+	if b.head > 0 && b.head >= len(b.entries)/2 {
+		n := copy(b.entries, b.entries[b.head:])
+		clear(b.entries[n:])
+		b.entries = b.entries[:n]
+		b.sizes = b.sizes[:copy(b.sizes, b.sizes[b.head:])]
+		b.head = 0
+	}
+}
+
+func (b *historyBuffer) len() int {
+	return len(b.entries) - b.head
+}
+
+// oldestSeq returns the sequence number of the oldest buffered entry, or
+// the next sequence number after current when the buffer is empty.
+func (b *historyBuffer) oldestSeq(current uint64) uint64 {
+	if b.len() == 0 {
+		return current + 1
+	}
+	return b.entries[b.head].Seq
+}
+
+// replay returns the buffered entries that c would have received, limited
+// to those at or after since, to those after the sequence number after, and
+// to the last n when n is positive.
+func (b *historyBuffer) replay(c *Client, n int, since time.Time, after uint64) []Entry {
+	cfg := c.config.Load()
+	var matched []Entry
+	for _, e := range b.entries[b.head:] {
+		if e.level < cfg.level || !c.matchesNamespace(e.Namespace) {
+			continue
+		}
+		if e.Seq <= after || (!since.IsZero() && e.Timestamp.Before(since)) {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	if n > 0 && len(matched) > n {
+		matched = matched[len(matched)-n:]
+	}
+	return matched
+}
+
+// entrySize approximates the memory held by e.
+func entrySize(e Entry) int {
+	size := entryOverhead + len(e.Output) + len(e.File) + len(e.Namespace)
+	size += len(e.Function) + len(e.Package)
+	for _, f := range e.Stack {
+		size += len(f.Function) + len(f.File) + 8
+	}
+	for k, v := range e.Fields {
+		size += len(k) + 16
+		if s, ok := v.(string); ok {
+			size += len(s)
+		}
+	}
+	return size
+}
+
+// ReplayGap reports the sequence numbers a client created with
+// [ClientOptions.ReplayAfter] asked for but could not be given because they
+// had already been evicted from history (or history is disabled). Entries
+// in the range may not all have matched the client's filters.
+func (c *Client) ReplayGap() (Gap, bool) {
+	if c.gap == nil {
+		return Gap{}, false
+	}
+	return *c.gap, true
+}
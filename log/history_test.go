@@ -0,0 +1,250 @@
+package log
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func historyOutputs(h *Hub) []string {
+	var out []string
+	for _, e := range h.History() {
+		out = append(out, e.Output)
+	}
+	return out
+}
+
+func TestHistoryEntryLimit(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	if h.History() != nil {
+		t.Error("history should be disabled by default")
+	}
+	h.SetHistoryLimits(3, 0)
+	l := h.NewLogger("history")
+	for i := 0; i < 10; i++ {
+		l.Info(strconv.Itoa(i))
+	}
+	if got := historyOutputs(h); !slices.Equal(got, []string{"7", "8", "9"}) {
+		t.Errorf("history = %v, want [7 8 9]", got)
+	}
+
+	h.SetHistoryLimits(1, 0)
+	if got := historyOutputs(h); !slices.Equal(got, []string{"9"}) {
+		t.Errorf("history after shrinking = %v, want [9]", got)
+	}
+
+	h.SetHistoryLimits(0, 0)
+	if h.History() != nil {
+		t.Error("history should be discarded when disabled")
+	}
+}
+
+func TestHistoryByteLimit(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	out := string(make([]byte, 100))
+	e := Entry{Output: out, Level: "INFO", Namespace: "bytes"}
+	h.SetHistoryLimits(0, 2*entrySize(e))
+	for i := 0; i < 5; i++ {
+		h.Broadcast(e)
+	}
+	if n := len(h.History()); n != 2 {
+		t.Errorf("history holds %d entries, want 2", n)
+	}
+}
+
+func TestReplayLast(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetHistoryLimits(100, 0)
+	l := h.NewLogger("replay")
+	other := h.NewLogger("replay-other")
+	for i := 0; i < 5; i++ {
+		l.Info(strconv.Itoa(i))
+		l.Debug("below level")
+		other.Info("other namespace")
+	}
+
+	c := h.CreateClientWithOptions(ClientOptions{
+		Namespaces: []string{"replay"},
+		Level:      LInfo,
+		ReplayLast: 3,
+	})
+	defer c.Destroy()
+	l.Info("live")
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		e, ok := getEntry(c, time.Second)
+		if !ok {
+			t.Fatal("timed out")
+		}
+		got = append(got, e.Output)
+	}
+	if want := []string{"2", "3", "4", "live"}; !slices.Equal(got, want) {
+		t.Errorf("received %v, want %v", got, want)
+	}
+	if len(c.writer) != 0 {
+		t.Errorf("unexpected extra entries: %d", len(c.writer))
+	}
+}
+
+func TestReplaySince(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetHistoryLimits(100, 0)
+	base := time.Now()
+	for i := 0; i < 4; i++ {
+		h.Broadcast(Entry{
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+			Output:    strconv.Itoa(i),
+			Level:     "INFO",
+			Namespace: "since",
+		})
+	}
+	c := h.CreateClientWithOptions(ClientOptions{ReplaySince: base.Add(2 * time.Second)})
+	defer c.Destroy()
+
+	for _, want := range []string{"2", "3"} {
+		e, ok := getEntry(c, time.Second)
+		if !ok {
+			t.Fatal("timed out")
+		}
+		if e.Output != want {
+			t.Errorf("output = %q, want %q", e.Output, want)
+		}
+	}
+}
+
+func TestReplayWithoutHistory(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.NewLogger("no-history").Info("before")
+	c := h.CreateClientWithOptions(ClientOptions{ReplayLast: 10})
+	defer c.Destroy()
+	if _, ok := c.nextBacklog(); ok {
+		t.Error("no entries should be replayed without history")
+	}
+}
+
+func TestReplaySink(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetHistoryLimits(10, 0)
+	h.NewLogger("replay-sink").Info("before")
+	s := &memorySink{}
+	h.AddSinkWithOptions(s, ClientOptions{ReplayLast: 10})
+	h.NewLogger("replay-sink").Info("after")
+	h.RemoveSink(s)
+	if got := s.outputs(); !slices.Equal(got, []string{"before", "after"}) {
+		t.Errorf("sink received %v, want [before after]", got)
+	}
+}
+
+func TestEntrySeq(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+	h.NewLogger("seq-a").Info("one")
+	h.NewLogger("seq-b").Info("two")
+	h.Broadcast(Entry{Output: "three", Level: "INFO", Seq: 100})
+	for want := uint64(1); want <= 3; want++ {
+		e, ok := getEntry(c, time.Second)
+		if !ok {
+			t.Fatal("timed out")
+		}
+		if e.Seq != want {
+			t.Errorf("seq = %d, want %d", e.Seq, want)
+		}
+	}
+}
+
+func TestReplayAfter(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetHistoryLimits(100, 0)
+	l := h.NewLogger("after")
+	for i := 1; i <= 5; i++ {
+		l.Info(strconv.Itoa(i))
+	}
+	c := h.CreateClientWithOptions(ClientOptions{ReplayAfter: 3})
+	defer c.Destroy()
+	if gap, ok := c.ReplayGap(); ok {
+		t.Errorf("unexpected gap %+v", gap)
+	}
+	for _, want := range []uint64{4, 5} {
+		e, ok := getEntry(c, time.Second)
+		if !ok {
+			t.Fatal("timed out")
+		}
+		if e.Seq != want {
+			t.Errorf("seq = %d, want %d", e.Seq, want)
+		}
+	}
+}
+
+func TestReplayAfterGap(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetHistoryLimits(3, 0)
+	l := h.NewLogger("gap")
+	for i := 1; i <= 10; i++ {
+		l.Info(strconv.Itoa(i))
+	}
+	c := h.CreateClientWithOptions(ClientOptions{ReplayAfter: 2})
+	defer c.Destroy()
+	gap, ok := c.ReplayGap()
+	if !ok || gap != (Gap{From: 3, To: 7}) {
+		t.Errorf("gap = %+v, %v; want {3 7}, true", gap, ok)
+	}
+	for _, want := range []uint64{8, 9, 10} {
+		e, ok := getEntry(c, time.Second)
+		if !ok {
+			t.Fatal("timed out")
+		}
+		if e.Seq != want {
+			t.Errorf("seq = %d, want %d", e.Seq, want)
+		}
+	}
+}
+
+func TestReplayAfterWithoutHistory(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	l := h.NewLogger("gap-no-history")
+	for i := 0; i < 4; i++ {
+		l.Info("x")
+	}
+	c := h.CreateClientWithOptions(ClientOptions{ReplayAfter: 1})
+	defer c.Destroy()
+	if gap, ok := c.ReplayGap(); !ok || gap != (Gap{From: 2, To: 4}) {
+		t.Errorf("gap = %+v, %v; want {2 4}, true", gap, ok)
+	}
+}
+
+func TestReplayAfterRestart(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetHistoryLimits(10, 0)
+	l := h.NewLogger("restart")
+	l.Info("1")
+	l.Info("2")
+	// A sequence number from before a restart replays the new history.
+	c := h.CreateClientWithOptions(ClientOptions{ReplayAfter: 500})
+	defer c.Destroy()
+	if gap, ok := c.ReplayGap(); ok {
+		t.Errorf("unexpected gap %+v", gap)
+	}
+	for _, want := range []uint64{1, 2} {
+		e, ok := getEntry(c, time.Second)
+		if !ok {
+			t.Fatal("timed out")
+		}
+		if e.Seq != want {
+			t.Errorf("seq = %d, want %d", e.Seq, want)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package log
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Hub owns a set of clients, sinks and a namespace registry. Entries logged
+// through a Hub reach only that Hub's clients and sinks, so independent
+// Hubs can run side by side in one process, e.g. in parallel tests.
+//
+// The package-level functions such as [Info], [CreateClient] and [AddSink]
+// operate on the default Hub returned by [DefaultHub]. Use [Hub.NewLogger]
+// or [WithHub] to log to a specific Hub.
+type Hub struct {
+	// clients is a copy-on-write snapshot of the registered clients.
+	// Dispatch loads it without locking; clientsMux serializes writers.
+	clients    atomic.Pointer[[]*Client]
+	clientsMux sync.Mutex
+
+	namespaces    map[string]*namespaceStats
+	namespacesMux sync.RWMutex
+	// Registry limits and the time of the last idle sweep, guarded by
+	// namespacesMux.
+	namespaceMaxIdle time.Duration
+	maxNamespaces    int
+	lastSweep        time.Time
+
+	sinks    map[Sink]*sinkRunner
+	sinksMux sync.Mutex
+	// sinkErrors, when set, is called for every failed Sink write.
+	sinkErrors atomic.Pointer[SinkErrorHandler]
+
+	// seq numbers every entry broadcast on the Hub. While history is
+	// enabled, numbers are assigned under the history lock so the buffer
+	// is always in sequence order.
+	seq     atomic.Uint64
+	history atomic.Pointer[historyBuffer]
+
+	// extractors is a copy-on-write list of context extractors.
+	extractors    atomic.Pointer[[]namedExtractor]
+	extractorsMux sync.Mutex
+
+	// levels is the current snapshot of global and namespace levels.
+	levels   atomic.Pointer[levelState]
+	levelCtl levelControl
+
+	// processors is a copy-on-write chain run before fan-out.
+	processors    atomic.Pointer[[]namedProcessor]
+	processorsMux sync.Mutex
+
+	// callerPolicy is the CallerPolicy of loggers that do not set one.
+	callerPolicy atomic.Int32
+
+	// stacks, when set, enables stack capture.
+	stacks atomic.Pointer[stackConfig]
+
+	// redactor, when set, scrubs secrets before fan-out.
+	redactor atomic.Pointer[redactor]
+
+	// rateLimits is a copy-on-write map of namespace limits.
+	rateLimits    atomic.Pointer[map[string]*limiter]
+	rateLimitsMux sync.Mutex
+
+	// dedup, when set, folds repeated entries before they are published.
+	dedup atomic.Pointer[deduper]
+
+	// closed is set by Close; entries logged afterwards are discarded.
+	closed atomic.Bool
+
+	// exitFunc, when set, replaces os.Exit in Fatal.
+	exitFunc atomic.Pointer[func(int)]
+}
+
+var defaultHub = NewHub()
+
+// NewHub returns an empty Hub with no clients and no sinks, and with the
+// built-in context extractors registered.
+func NewHub() *Hub {
+	h := &Hub{
+		namespaces: make(map[string]*namespaceStats),
+		sinks:      make(map[Sink]*sinkRunner),
+	}
+	h.RegisterContextExtractor("traceparent", TraceparentExtractor)
+	h.RegisterContextExtractor("request_id", RequestIDExtractor)
+	h.RegisterContextExtractor("user_id", UserIDExtractor)
+	return h
+}
+
+// DefaultHub returns the Hub used by the package-level functions. It is the
+// only Hub with the stderr sink registered.
+func DefaultHub() *Hub {
+	return defaultHub
+}
+
+// NewLogger returns a Logger for namespace that logs to h.
+func (h *Hub) NewLogger(namespace string, opts ...LoggerOption) *Logger {
+	l := NewLogger(namespace, opts...)
+	l.hub = h
+	return l
+}
+
+// loadClients returns the current subscriber snapshot. The returned slice
+// must not be modified.
+func (h *Hub) loadClients() []*Client {
+	if p := h.clients.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// CreateClient registers a new client on h. See [CreateClient].
+func (h *Hub) CreateClient(namespaces ...string) *Client {
+	return h.CreateClientWithOptions(ClientOptions{Namespaces: namespaces})
+}
+
+// CreateClientWithOptions registers a new client on h configured by opts.
+func (h *Hub) CreateClientWithOptions(opts ClientOptions) *Client {
+	bufferSize := opts.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	client := &Client{
+		LogLevel:   opts.Level,
+		Namespaces: opts.Namespaces,
+		filter:     newNamespaceFilter(opts.Namespaces),
+		writer:     make(LogWriter, bufferSize),
+		hub:        h,
+	}
+	client.config.Store(&clientConfig{
+		level:        opts.Level,
+		overflow:     opts.Overflow,
+		blockTimeout: opts.BlockTimeout,
+		sampleRate:   opts.SampleRate,
+	})
+	client.initialized.Store(true)
+	replay := opts.ReplayLast > 0 || !opts.ReplaySince.IsZero() || opts.ReplayAfter > 0
+	hist := h.history.Load()
+	if !replay {
+		h.addClient(client)
+		return client
+	}
+	if hist == nil {
+		h.addClient(client)
+		// Every entry up to the current sequence may have been missed;
+		// later ones are dispatched to the client now that it is added.
+		seq := h.seq.Load()
+		if after := resumeAfter(opts.ReplayAfter, seq); opts.ReplayAfter > 0 && after < seq {
+			client.gap = &Gap{From: after + 1, To: seq}
+		}
+		return client
+	}
+	// Holding the history lock while subscribing means every entry is
+	// either already buffered (and replayed) or dispatched to the new
+	// client live; minSeq discards live entries that were also replayed.
+	hist.mux.Lock()
+	seq := h.seq.Load()
+	after := resumeAfter(opts.ReplayAfter, seq)
+	if opts.ReplayAfter > 0 {
+		if oldest := hist.oldestSeq(seq); after+1 < oldest {
+			client.gap = &Gap{From: after + 1, To: oldest - 1}
+		}
+	}
+	client.backlog = hist.replay(client, opts.ReplayLast, opts.ReplaySince, after)
+	client.minSeq = seq
+	h.addClient(client)
+	hist.mux.Unlock()
+	return client
+}
+
+// resumeAfter returns the sequence number to resume after. A value beyond
+// current was seen before the Hub restarted, so the consumer resumes from
+// the start of the new sequence.
+func resumeAfter(after, current uint64) uint64 {
+	if after > current {
+		return 0
+	}
+	return after
+}
+
+func (h *Hub) addClient(c *Client) {
+	h.clientsMux.Lock()
+	current := h.loadClients()
+	next := make([]*Client, len(current), len(current)+1)
+	copy(next, current)
+	next = append(next, c)
+	h.clients.Store(&next)
+	h.clientsMux.Unlock()
+}
+
+// removeClient marks c as destroyed and removes it from the snapshot.
+func (h *Hub) removeClient(c *Client) {
+	h.clientsMux.Lock()
+	c.initialized.Store(false)
+	current := h.loadClients()
+	next := make([]*Client, 0, len(current))
+	for _, x := range current {
+		if x != c && x.initialized.Load() {
+			next = append(next, x)
+		}
+	}
+	h.clients.Store(&next)
+	h.clientsMux.Unlock()
+}
+
+// Broadcast sends e to the clients and sinks of h. See [Broadcast].
+func (h *Hub) Broadcast(e Entry) {
+	if e.level == 0 && e.Level != "" && e.Level != "TRACE" {
+		e.level = parseLevelString(e.Level)
+	}
+	h.createLog(e)
+}
+
+func (h *Hub) createLog(e Entry) {
+	if h.closed.Load() || e.level < h.levelFor(e.Namespace) || h.rateLimited(e) {
+		return
+	}
+	if sc := h.stacks.Load(); sc != nil && e.level >= sc.MinLevel && e.Stack == nil {
+		e.Stack = sc.captureStack(1)
+	}
+	if !h.process(&e) {
+		return
+	}
+	e.Fields = normalizeFields(e.Fields)
+	if r := h.redactor.Load(); r != nil {
+		if n := r.redact(&e); n > 0 {
+			h.lookupNamespace(e.Namespace, time.Now()).redacted.Add(uint64(n))
+		}
+	}
+	if d := h.dedup.Load(); d != nil && !d.admit(e) {
+		return
+	}
+	h.publish(e)
+}
+
+// publish numbers e and delivers it to every client.
+func (h *Hub) publish(e Entry) {
+	h.recordNamespace(e)
+	if hist := h.history.Load(); hist != nil {
+		hist.mux.Lock()
+		e.Seq = h.seq.Add(1)
+		hist.push(e)
+		hist.mux.Unlock()
+	} else {
+		e.Seq = h.seq.Add(1)
+	}
+	for _, c := range h.loadClients() {
+		c.dispatch(e)
+	}
+}
+
+// Flush writes out every entry still queued for a Sink registered on h,
+// including pending duplicate summaries, and flushes the sinks. Sinks stay
+// registered, so Flush can be called any number of times and logging keeps
+// working afterwards. Entries logged concurrently with Flush may be written
+// after it returns.
+func (h *Hub) Flush() {
+	if d := h.dedup.Load(); d != nil {
+		d.flush()
+	}
+	h.flushSinks()
+}
+
+// SetExitFunc replaces [os.Exit] as the function Fatal calls on h after
+// flushing, e.g. so a test can observe a fatal path. If fn returns, so does
+// the Fatal call. A nil fn restores os.Exit.
+func (h *Hub) SetExitFunc(fn func(code int)) {
+	if fn == nil {
+		h.exitFunc.Store(nil)
+		return
+	}
+	h.exitFunc.Store(&fn)
+}
+
+// exit flushes h and terminates the process with code, or calls the
+// function set with SetExitFunc.
+func (h *Hub) exit(code int) {
+	h.Flush()
+	if fn := h.exitFunc.Load(); fn != nil {
+		(*fn)(code)
+		return
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,105 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHubIsolation(t *testing.T) {
+	t.Parallel()
+	hubA, hubB := NewHub(), NewHub()
+	a := hubA.CreateClient()
+	defer a.Destroy()
+	b := hubB.CreateClient()
+	defer b.Destroy()
+
+	hubA.NewLogger("hub-a").Info("from a")
+	NewLogger("hub-b", WithHub(hubB)).Info("from b")
+
+	e, ok := getEntry(a, time.Second)
+	if !ok || e.Output != "from a" {
+		t.Fatalf("hub A got %q (ok=%v), want %q", e.Output, ok, "from a")
+	}
+	e, ok = getEntry(b, time.Second)
+	if !ok || e.Output != "from b" {
+		t.Fatalf("hub B got %q (ok=%v), want %q", e.Output, ok, "from b")
+	}
+	if len(a.writer) != 0 || len(b.writer) != 0 {
+		t.Error("entries leaked between hubs")
+	}
+
+	if got := hubA.GetNamespaces(); !slices.Equal(got, []string{"hub-a"}) {
+		t.Errorf("hub A namespaces = %v, want [hub-a]", got)
+	}
+	if slices.Contains(GetNamespaces(), "hub-a") {
+		t.Error("hub-local namespace leaked into the default hub")
+	}
+}
+
+func TestHubCreateDestroy(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient("x")
+	if n := len(h.loadClients()); n != 1 {
+		t.Fatalf("expected 1 client, found %d", n)
+	}
+	c.Destroy()
+	if n := len(h.loadClients()); n != 0 {
+		t.Errorf("expected 0 clients, found %d", n)
+	}
+}
+
+func TestHubSinksAndFlush(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	s := &memorySink{}
+	if err := h.AddSink(s, LTrace); err != nil {
+		t.Fatal(err)
+	}
+	h.Broadcast(Entry{Output: "broadcast", Level: "WARN", Namespace: "hub-sink"})
+	h.Flush()
+	if got := s.outputs(); !slices.Equal(got, []string{"broadcast"}) {
+		t.Errorf("sink received %v, want [broadcast]", got)
+	}
+	// Flush leaves the sink registered, so it can be called again.
+	h.Broadcast(Entry{Output: "again", Level: "WARN", Namespace: "hub-sink"})
+	h.Flush()
+	if got := s.outputs(); !slices.Equal(got, []string{"broadcast", "again"}) {
+		t.Errorf("sink received %v, want [broadcast again]", got)
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.flushed != 2 || s.closed != 0 {
+		t.Errorf("flushed=%d closed=%d, want 2 and 0", s.flushed, s.closed)
+	}
+}
+
+func TestDefaultHub(t *testing.T) {
+	if DefaultHub() != defaultHub {
+		t.Error("DefaultHub() should return the package default hub")
+	}
+	if Default().getHub() != DefaultHub() {
+		t.Error("Default() logger should log to the default hub")
+	}
+}
+
+func TestHubNormalizesFields(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+	h.NewLogger("fields").With("err", errors.New("boom"), "fn", func() {}).Info("normalized")
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.Fields["err"] != "boom" {
+		t.Errorf("err = %#v, want the error message", e.Fields["err"])
+	}
+	if _, err := json.Marshal(e); err != nil {
+		t.Errorf("entry does not encode: %v", err)
+	}
+}
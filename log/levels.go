@@ -0,0 +1,262 @@
+package log
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LevelSetting is a minimum level in effect on a Hub and, for a temporary
+// change, when it reverts.
+type LevelSetting struct {
+	Level Level
+	// RevertAt is when the setting is undone, or zero if it is permanent.
+	RevertAt time.Time
+}
+
+// LevelConfig is a snapshot of a Hub's minimum levels.
+type LevelConfig struct {
+	Global     LevelSetting
+	Namespaces map[string]LevelSetting
+}
+
+// levelState is an immutable snapshot of the Hub's levels, read without
+// locking on every logging call.
+type levelState struct {
+	global     Level
+	namespaces map[string]Level
+}
+
+// levelRevert undoes a temporary level change.
+type levelRevert struct {
+	at     time.Time
+	timer  *time.Timer
+	prev   Level
+	hadOld bool
+}
+
+// levelControl holds the Hub's pending reverts, guarded by mux.
+type levelControl struct {
+	mux     sync.Mutex
+	global  *levelRevert
+	reverts map[string]*levelRevert
+}
+
+// ParseLevel returns the Level named s, such as "INFO" or "warn".
+func ParseLevel(s string) (Level, error) {
+	for l := LTrace; l <= LFatal; l++ {
+		if strings.EqualFold(s, l.String()) {
+			return l, nil
+		}
+	}
+	return LTrace, fmt.Errorf("unknown log level %q", s)
+}
+
+// SetGlobalLevel sets the default Hub's minimum level. See
+// [Hub.SetGlobalLevel].
+func SetGlobalLevel(level Level) {
+	defaultHub.SetGlobalLevel(level)
+}
+
+// SetGlobalLevelFor sets the default Hub's minimum level for d. See
+// [Hub.SetGlobalLevelFor].
+func SetGlobalLevelFor(level Level, d time.Duration) {
+	defaultHub.SetGlobalLevelFor(level, d)
+}
+
+// SetNamespaceLevel sets the minimum level of namespace on the default Hub.
+// See [Hub.SetNamespaceLevel].
+func SetNamespaceLevel(namespace string, level Level) {
+	defaultHub.SetNamespaceLevel(namespace, level)
+}
+
+// SetNamespaceLevelFor sets the minimum level of namespace on the default
+// Hub for d. See [Hub.SetNamespaceLevelFor].
+func SetNamespaceLevelFor(namespace string, level Level, d time.Duration) {
+	defaultHub.SetNamespaceLevelFor(namespace, level, d)
+}
+
+// ClearNamespaceLevel removes the level set for namespace on the default
+// Hub. See [Hub.ClearNamespaceLevel].
+func ClearNamespaceLevel(namespace string) {
+	defaultHub.ClearNamespaceLevel(namespace)
+}
+
+// ClearNamespaceLevelFor removes the level set for namespace on the default
+// Hub for d. See [Hub.ClearNamespaceLevelFor].
+func ClearNamespaceLevelFor(namespace string, d time.Duration) {
+	defaultHub.ClearNamespaceLevelFor(namespace, d)
+}
+
+// Levels returns the default Hub's minimum levels.
+func Levels() LevelConfig {
+	return defaultHub.Levels()
+}
+
+// SetGlobalLevel sets the minimum level of every entry logged on h, except
+// in namespaces with their own level (see [Hub.SetNamespaceLevel]). Entries
+// below it are dropped before they reach any client or sink, and calls on a
+// [Logger] return before formatting. The default is LTrace. Levels set on a
+// Logger, client or sink still apply.
+func (h *Hub) SetGlobalLevel(level Level) {
+	h.setLevel("", true, level, true, 0)
+}
+
+// SetGlobalLevelFor sets the global level like [Hub.SetGlobalLevel], and
+// restores the previous one after d. If d is not positive, the change is
+// permanent.
+func (h *Hub) SetGlobalLevelFor(level Level, d time.Duration) {
+	h.setLevel("", true, level, true, d)
+}
+
+// SetNamespaceLevel sets the minimum level of namespace, overriding the
+// global level in either direction, e.g. to turn on TRACE for one noisy
+// subsystem while the rest of the Hub stays at INFO.
+func (h *Hub) SetNamespaceLevel(namespace string, level Level) {
+	h.setLevel(namespace, false, level, true, 0)
+}
+
+// SetNamespaceLevelFor sets the level of namespace like
+// [Hub.SetNamespaceLevel], and restores the previous setting after d. If d
+// is not positive, the change is permanent.
+func (h *Hub) SetNamespaceLevelFor(namespace string, level Level, d time.Duration) {
+	h.setLevel(namespace, false, level, true, d)
+}
+
+// ClearNamespaceLevel removes the level set for namespace, which then
+// follows the global level again.
+func (h *Hub) ClearNamespaceLevel(namespace string) {
+	h.setLevel(namespace, false, 0, false, 0)
+}
+
+// ClearNamespaceLevelFor removes the level set for namespace like
+// [Hub.ClearNamespaceLevel], and restores the previous setting after d. If
+// d is not positive, the change is permanent. While the clear is pending,
+// [Hub.Levels] does not list namespace.
+func (h *Hub) ClearNamespaceLevelFor(namespace string, d time.Duration) {
+	h.setLevel(namespace, false, 0, false, d)
+}
+
+// Levels returns h's global level and every namespace level, with the
+// time each temporary setting reverts.
+func (h *Hub) Levels() LevelConfig {
+	h.levelCtl.mux.Lock()
+	defer h.levelCtl.mux.Unlock()
+	st := h.loadLevels()
+	cfg := LevelConfig{
+		Global:     LevelSetting{Level: st.global},
+		Namespaces: make(map[string]LevelSetting, len(st.namespaces)),
+	}
+	if r := h.levelCtl.global; r != nil {
+		cfg.Global.RevertAt = r.at
+	}
+	for ns, level := range st.namespaces {
+		s := LevelSetting{Level: level}
+		if r := h.levelCtl.reverts[ns]; r != nil {
+			s.RevertAt = r.at
+		}
+		cfg.Namespaces[ns] = s
+	}
+	return cfg
+}
+
+// levelFor returns the minimum level of entries in namespace.
+func (h *Hub) levelFor(namespace string) Level {
+	st := h.levels.Load()
+	if st == nil {
+		return LTrace
+	}
+	if level, ok := st.namespaces[namespace]; ok {
+		return level
+	}
+	return st.global
+}
+
+func (h *Hub) loadLevels() levelState {
+	if st := h.levels.Load(); st != nil {
+		return *st
+	}
+	return levelState{global: LTrace}
+}
+
+// setLevel changes the global level, or namespace's level, reverting it
+// after d when d is positive. For a namespace, set false removes its level.
+func (h *Hub) setLevel(namespace string, global bool, level Level, set bool, d time.Duration) {
+	c := &h.levelCtl
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	pending := c.global
+	if !global {
+		pending = c.reverts[namespace]
+	}
+	if pending != nil {
+		pending.timer.Stop()
+	}
+	var next *levelRevert
+	if d > 0 {
+		next = &levelRevert{at: time.Now().Add(d)}
+		if pending != nil {
+			// Keep reverting to the setting from before any temporary
+			// change.
+			next.prev, next.hadOld = pending.prev, pending.hadOld
+		} else {
+			st := h.loadLevels()
+			if global {
+				next.prev, next.hadOld = st.global, true
+			} else {
+				next.prev, next.hadOld = st.namespaces[namespace]
+			}
+		}
+		next.timer = time.AfterFunc(d, func() { h.revertLevel(namespace, global, next) })
+	}
+	if global {
+		c.global = next
+	} else if next != nil {
+		if c.reverts == nil {
+			c.reverts = make(map[string]*levelRevert)
+		}
+		c.reverts[namespace] = next
+	} else {
+		delete(c.reverts, namespace)
+	}
+	h.storeLevelLocked(namespace, global, level, set)
+}
+
+func (h *Hub) revertLevel(namespace string, global bool, r *levelRevert) {
+	c := &h.levelCtl
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if global {
+		if c.global != r {
+			return
+		}
+		c.global = nil
+	} else {
+		if c.reverts[namespace] != r {
+			return
+		}
+		delete(c.reverts, namespace)
+	}
+	h.storeLevelLocked(namespace, global, r.prev, r.hadOld)
+}
+
+// storeLevelLocked publishes a new level snapshot. For a namespace, set
+// false removes its level.
+func (h *Hub) storeLevelLocked(namespace string, global bool, level Level, set bool) {
+	st := h.loadLevels()
+	next := levelState{global: st.global, namespaces: maps.Clone(st.namespaces)}
+	switch {
+	case global:
+		next.global = level
+	case set:
+		if next.namespaces == nil {
+			next.namespaces = make(map[string]Level)
+		}
+		next.namespaces[namespace] = level
+	default:
+		delete(next.namespaces, namespace)
+	}
+	h.levels.Store(&next)
+}
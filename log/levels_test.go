@@ -0,0 +1,120 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	for l := LTrace; l <= LFatal; l++ {
+		got, err := ParseLevel(l.String())
+		if err != nil || got != l {
+			t.Errorf("ParseLevel(%q) = %v, %v", l.String(), got, err)
+		}
+	}
+	if got, err := ParseLevel("warn"); err != nil || got != LWarn {
+		t.Errorf("ParseLevel is not case-insensitive: %v, %v", got, err)
+	}
+	if _, err := ParseLevel("LOUD"); err == nil {
+		t.Error("ParseLevel accepted an unknown level")
+	}
+}
+
+func TestHubLevels(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+	h.SetGlobalLevel(LWarn)
+	h.SetNamespaceLevel("database", LTrace)
+
+	api := h.NewLogger("api")
+	db := h.NewLogger("database")
+	api.Info("dropped")
+	api.Warn("kept")
+	db.Trace("kept")
+	h.Broadcast(Entry{Output: "dropped", Level: "DEBUG", Namespace: "api"})
+	if n := drain(c); n != 2 {
+		t.Errorf("delivered %d entries, want 2", n)
+	}
+
+	h.ClearNamespaceLevel("database")
+	db.Trace("dropped")
+	if n := drain(c); n != 0 {
+		t.Errorf("delivered %d entries after clearing, want 0", n)
+	}
+
+	cfg := h.Levels()
+	if cfg.Global.Level != LWarn || len(cfg.Namespaces) != 0 {
+		t.Errorf("Levels = %+v", cfg)
+	}
+}
+
+func TestHubLevelRevert(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetGlobalLevel(LInfo)
+	h.SetNamespaceLevel("database", LWarn)
+	h.SetNamespaceLevelFor("database", LTrace, 50*time.Millisecond)
+	// A second temporary change still reverts to the original setting.
+	h.SetNamespaceLevelFor("database", LDebug, 50*time.Millisecond)
+	h.SetNamespaceLevelFor("cache", LTrace, 50*time.Millisecond)
+	h.SetGlobalLevelFor(LError, 50*time.Millisecond)
+
+	cfg := h.Levels()
+	if s := cfg.Namespaces["database"]; s.Level != LDebug || s.RevertAt.IsZero() {
+		t.Errorf("database = %+v, want DEBUG with a revert time", s)
+	}
+	if cfg.Global.Level != LError || cfg.Global.RevertAt.IsZero() {
+		t.Errorf("global = %+v, want ERROR with a revert time", cfg.Global)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		cfg = h.Levels()
+		if cfg.Global.RevertAt.IsZero() && len(cfg.Namespaces) == 1 && cfg.Namespaces["database"].RevertAt.IsZero() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if cfg.Global != (LevelSetting{Level: LInfo}) {
+		t.Errorf("global after revert = %+v, want INFO", cfg.Global)
+	}
+	if s, ok := cfg.Namespaces["database"]; !ok || s != (LevelSetting{Level: LWarn}) {
+		t.Errorf("database after revert = %+v, want WARN", s)
+	}
+	if _, ok := cfg.Namespaces["cache"]; ok {
+		t.Error("cache level should be removed after revert")
+	}
+}
+
+func TestHubClearNamespaceLevelFor(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetNamespaceLevel("database", LWarn)
+	h.ClearNamespaceLevelFor("database", 20*time.Millisecond)
+	if _, ok := h.Levels().Namespaces["database"]; ok {
+		t.Fatal("database level should be cleared")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := h.Levels().Namespaces["database"]; ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if s := h.Levels().Namespaces["database"]; s != (LevelSetting{Level: LWarn}) {
+		t.Errorf("database after revert = %+v, want WARN", s)
+	}
+}
+
+func TestHubLevelPermanentCancelsRevert(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetNamespaceLevelFor("database", LTrace, 20*time.Millisecond)
+	h.SetNamespaceLevel("database", LError)
+	time.Sleep(60 * time.Millisecond)
+	if s := h.Levels().Namespaces["database"]; s != (LevelSetting{Level: LError}) {
+		t.Errorf("database = %+v, want a permanent ERROR", s)
+	}
+}
@@ -4,161 +4,125 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
-	"runtime"
-	"strings"
-	"sync"
 	"time"
 )
 
-var (
-	clients        []*Client
-	sliceTex       sync.Mutex
-	stderrClient   *Client
-	cleanup        sync.Once
-	stderrFinished chan bool
-	namespaces     map[string]bool
-	namespacesMux  sync.RWMutex
-)
-
 func init() {
-	namespaces = make(map[string]bool)
 	initColorEnabled()
-	stderrClient = CreateClient()
-	stderrClient.SetLogLevel(LTrace)
-	stderrFinished = make(chan bool, 1)
-	go stderrClient.logStdErr()
-}
-
-func (c *Client) logStdErr() {
-	for e := range c.writer {
-		if e.level >= c.LogLevel && c.matchesNamespace(e.Namespace) {
-			levelStr := colorizeLevelText(e.Level, e.level)
-			nsStr := colorize("["+e.Namespace+"]", colorPurple)
-			fileStr := colorize(e.File, colorGray)
-			fmt.Fprintf(os.Stderr, "%s\t%s\t%s\t%s\t%s\n", e.Timestamp.String(), levelStr, nsStr, e.Output, fileStr)
-		}
-	}
-	stderrFinished <- true
+	initStderrSink()
 }
 
 func (c *Client) matchesNamespace(namespace string) bool {
-	// Empty Namespaces slice means match all
-	if len(c.Namespaces) == 0 {
-		return true
-	}
-	for _, ns := range c.Namespaces {
-		if ns == namespace {
-			return true
-		}
-	}
-	return false
+	return c.filter.match(namespace)
+}
+
+// CreateClient registers a new client that receives entries from the given
+// namespaces, or from all namespaces if none are given. The client uses a
+// buffer of [DefaultBufferSize] entries and the [OverflowDropOldest] policy.
+//
+// Namespaces may be glob patterns, where * matches any run of characters
+// including dots: "payments.*" matches every namespace below payments and
+// "*.db" every namespace ending in .db. A pattern prefixed with ! excludes
+// matching namespaces; if only exclusions are given, every other namespace
+// is included.
+func CreateClient(namespaces ...string) *Client {
+	return defaultHub.CreateClient(namespaces...)
 }
 
-func CreateClient(namespaces ...string) *Client {
-	var client Client
-	client.initialized = true
-	client.Namespaces = namespaces
-	client.writer = make(LogWriter, 1000)
-	sliceTex.Lock()
-	clients = append(clients, &client)
-	sliceTex.Unlock()
-	return &client
+// CreateClientWithOptions registers a new client configured by opts.
+func CreateClientWithOptions(opts ClientOptions) *Client {
+	return defaultHub.CreateClientWithOptions(opts)
 }
 
+// Flush writes out every entry still queued for a registered [Sink] and
+// flushes the sinks, which stay registered. It is safe to call repeatedly,
+// and is called by Panic and Fatal before they panic or exit.
 func Flush() {
-	cleanup.Do(func() {
-		close(stderrClient.writer)
-		<-stderrFinished
-		stderrClient.Destroy()
-	})
+	defaultHub.Flush()
+}
+
+// SetExitFunc replaces [os.Exit] in [Fatal] and the Fatal methods of
+// loggers on the default Hub. See [Hub.SetExitFunc].
+func SetExitFunc(fn func(code int)) {
+	defaultHub.SetExitFunc(fn)
 }
 
 func (c *Client) Destroy() error {
-	if !c.initialized {
+	if !c.initialized.Load() {
 		panic(errors.New("cannot delete uninitialized client, did you use CreateClient?"))
 	}
-	sliceTex.Lock()
-	c.initialized = false
-	var otherClients []*Client
-	for _, x := range clients {
-		if x != c && x.initialized {
-			otherClients = append(otherClients, x)
-		}
-	}
-	clients = otherClients
-	c.writer = nil
-	sliceTex.Unlock()
+	c.hub.removeClient(c)
 	return nil
 }
 
 func (c *Client) GetLogLevel() Level {
-	if !c.initialized {
+	if !c.initialized.Load() {
 		panic(errors.New("cannot get level for uninitialized client, use CreateClient instead"))
 	}
-	return c.LogLevel
+	return c.config.Load().level
+}
+
+// updateConfig replaces the client's dispatch configuration with a copy
+// modified by fn. Dispatch never observes a partially updated config.
+func (c *Client) updateConfig(fn func(*clientConfig)) {
+	c.configMux.Lock()
+	cfg := *c.config.Load()
+	fn(&cfg)
+	c.config.Store(&cfg)
+	c.LogLevel = cfg.level
+	c.configMux.Unlock()
 }
 
 func createLog(e Entry) {
-	// Track namespace
-	namespacesMux.Lock()
-	namespaces[e.Namespace] = true
-	namespacesMux.Unlock()
-
-	sliceTex.Lock()
-	for _, c := range clients {
-		func(c *Client, e Entry) {
-			if c.writer == nil || !c.initialized {
-				return
-			}
-			// Filter by namespace if client has filters specified
-			if !c.matchesNamespace(e.Namespace) {
-				return
-			}
-			select {
-			case c.writer <- e:
-				// try to clear out one of the older entries
-			default:
-				select {
-				case <-c.writer:
-					c.writer <- e
-				default:
-				}
-			}
-		}(c, e)
-	}
-	sliceTex.Unlock()
+	defaultHub.createLog(e)
+}
+
+// dispatch delivers e to the client if it passes the client's level and
+// namespace filters.
+func (c *Client) dispatch(e Entry) {
+	if !c.initialized.Load() {
+		return
+	}
+	if e.Seq <= c.minSeq {
+		return
+	}
+	cfg := c.config.Load()
+	// Filter by level and by namespace if client has filters specified
+	if e.level < cfg.level || !c.matchesNamespace(e.Namespace) {
+		return
+	}
+	c.deliver(e, cfg)
 }
 
 // GetNamespaces returns a list of all namespaces that have been used
 func GetNamespaces() []string {
-	namespacesMux.RLock()
-	defer namespacesMux.RUnlock()
-
-	result := make([]string, 0, len(namespaces))
-	for ns := range namespaces {
-		result = append(result, ns)
-	}
-	return result
+	return defaultHub.GetNamespaces()
 }
 
+// SetLogLevel sets the minimum level printed to stderr.
 func SetLogLevel(level Level) {
-	stderrClient.LogLevel = level
+	SetSinkLevel(stderrSink, level)
 }
 
-// SetLogLevel set log level of logger
+// SetLogLevel sets the minimum level delivered to the client. Entries below
+// level are filtered out during dispatch and never reach the client's buffer.
 func (c *Client) SetLogLevel(level Level) {
-	if !c.initialized {
+	if !c.initialized.Load() {
 		panic(errors.New("cannot set level for uninitialized client, use CreateClient instead"))
 	}
-	c.LogLevel = level
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.level = level
+	})
 }
 
 // Get blocks until a log entry is available and returns it.
 func (c *Client) Get() Entry {
-	if !c.initialized {
+	if !c.initialized.Load() {
 		panic(errors.New("cannot get logs for uninitialized client, did you use CreateClient?"))
 	}
+	if e, ok := c.nextBacklog(); ok {
+		return e
+	}
 	return <-c.writer
 }
 
@@ -166,9 +130,12 @@ func (c *Client) Get() Entry {
 // The second return value is false when the context was cancelled before
 // an entry arrived.
 func (c *Client) GetContext(ctx context.Context) (Entry, bool) {
-	if !c.initialized {
+	if !c.initialized.Load() {
 		panic(errors.New("cannot get logs for uninitialized client, did you use CreateClient?"))
 	}
+	if e, ok := c.nextBacklog(); ok {
+		return e, true
+	}
 	select {
 	case e := <-c.writer:
 		return e, true
@@ -177,17 +144,30 @@ func (c *Client) GetContext(ctx context.Context) (Entry, bool) {
 	}
 }
 
+// nextBacklog pops the oldest replayed entry, if any remain.
+func (c *Client) nextBacklog() (Entry, bool) {
+	c.backlogMux.Lock()
+	defer c.backlogMux.Unlock()
+	if len(c.backlog) == 0 {
+		return Entry{}, false
+	}
+	e := c.backlog[0]
+	c.backlog[0] = Entry{}
+	c.backlog = c.backlog[1:]
+	return e, true
+}
+
 // Trace prints out logs on trace level
 func Trace(args ...any) {
 	output := fmt.Sprint(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "TRACE",
 		Namespace: DefaultNamespace,
 		level:     LTrace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -197,11 +177,11 @@ func Tracef(format string, args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "TRACE",
 		level:     LTrace,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -211,11 +191,11 @@ func Traceln(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "TRACE",
 		level:     LTrace,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -225,11 +205,11 @@ func Debug(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "DEBUG",
 		level:     LDebug,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -239,11 +219,11 @@ func Debugf(format string, args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "DEBUG",
 		level:     LDebug,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -253,11 +233,11 @@ func Debugln(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "DEBUG",
 		level:     LDebug,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -267,11 +247,11 @@ func Info(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "INFO",
 		level:     LInfo,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -281,11 +261,11 @@ func Infof(format string, args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "INFO",
 		level:     LInfo,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -295,11 +275,11 @@ func Infoln(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "INFO",
 		level:     LInfo,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -309,11 +289,11 @@ func Notice(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "NOTICE",
 		level:     LNotice,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -323,11 +303,11 @@ func Noticef(format string, args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "NOTICE",
 		level:     LNotice,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -337,11 +317,11 @@ func Noticeln(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "NOTICE",
 		level:     LNotice,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -351,11 +331,11 @@ func Warn(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "WARN",
 		level:     LWarn,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -365,11 +345,11 @@ func Warnf(format string, args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "WARN",
 		level:     LWarn,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -379,11 +359,11 @@ func Warnln(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "WARN",
 		level:     LWarn,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -393,11 +373,11 @@ func Error(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "ERROR",
 		level:     LError,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -407,11 +387,11 @@ func Errorf(format string, args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "ERROR",
 		level:     LError,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -421,11 +401,11 @@ func Errorln(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "ERROR",
 		level:     LError,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
 }
 
@@ -435,12 +415,13 @@ func Panic(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "PANIC",
 		level:     LPanic,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
+	Flush()
 	if len(args) > 0 {
 		switch args[0].(type) {
 		case error:
@@ -449,7 +430,6 @@ func Panic(args ...any) {
 			// falls through to default below
 		}
 	}
-	Flush()
 	panic(errors.New(output))
 }
 
@@ -459,12 +439,13 @@ func Panicf(format string, args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "PANIC",
 		level:     LPanic,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
+	Flush()
 	if len(args) > 0 {
 		switch args[0].(type) {
 		case error:
@@ -473,7 +454,6 @@ func Panicf(format string, args ...any) {
 			// falls through to default below
 		}
 	}
-	Flush()
 	panic(errors.New(output))
 }
 
@@ -482,12 +462,13 @@ func Panicln(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "PANIC",
 		level:     LPanic,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
+	Flush()
 	if len(args) > 0 {
 		switch args[0].(type) {
 		case error:
@@ -496,7 +477,6 @@ func Panicln(args ...any) {
 			// falls through to default below
 		}
 	}
-	Flush()
 	panic(errors.New(output))
 }
 
@@ -506,14 +486,13 @@ func Fatal(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "FATAL",
 		level:     LFatal,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
-	Flush()
-	os.Exit(1)
+	defaultHub.exit(1)
 }
 
 // Formatted print for fatal
@@ -522,14 +501,13 @@ func Fatalf(format string, args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "FATAL",
 		level:     LFatal,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
-	Flush()
-	os.Exit(1)
+	defaultHub.exit(1)
 }
 
 func Fatalln(args ...any) {
@@ -537,14 +515,13 @@ func Fatalln(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2),
 		Level:     "FATAL",
 		level:     LFatal,
 		Namespace: DefaultNamespace,
 	}
+	defaultHub.setCaller(&e, CallerDefault, 2)
 	createLog(e)
-	Flush()
-	os.Exit(1)
+	defaultHub.exit(1)
 }
 
 func Print(args ...any) {
@@ -559,30 +536,13 @@ func Println(args ...any) {
 	Infoln(args...)
 }
 
-// fileInfo for getting which line in which file
-func fileInfo(skip int) string {
-	_, file, line, ok := runtime.Caller(skip)
-	if !ok {
-		file = "<???>"
-		line = 1
-	} else {
-		slash := strings.LastIndex(file, "/")
-		if slash >= 0 {
-			file = file[slash+1:]
-		}
-	}
-	return fmt.Sprintf("%s:%d", file, line)
-}
-
 // Broadcast sends an [Entry] to all registered clients. This is the public
 // entry point used by adapter packages (such as the slog handler) that
 // construct entries themselves. The unexported level field is inferred from
-// [Entry.Level] when not already set.
+// [Entry.Level] when not already set, and [Entry.Seq] is always assigned by
+// the Hub.
 func Broadcast(e Entry) {
-	if e.level == 0 && e.Level != "" && e.Level != "TRACE" {
-		e.level = parseLevelString(e.Level)
-	}
-	createLog(e)
+	defaultHub.Broadcast(e)
 }
 
 func parseLevelString(s string) Level {
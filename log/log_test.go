@@ -23,18 +23,61 @@ func getEntry(c *Client, timeout time.Duration) (Entry, bool) {
 // Test CreateClient() and Client.Destroy()
 func TestCreateDestroy(t *testing.T) {
 	// Ensure only stderr exists at the beginning
-	if len(clients) != 1 {
-		t.Errorf("Expected 1 client, but found %d", len(clients))
+	if len(defaultHub.loadClients()) != 1 {
+		t.Errorf("Expected 1 client, but found %d", len(defaultHub.loadClients()))
 	}
 	// Create a new client, ensure it's added
 	c := CreateClient("test")
-	if len(clients) != 2 {
-		t.Errorf("Expected 2 clients, but found %d", len(clients))
+	if len(defaultHub.loadClients()) != 2 {
+		t.Errorf("Expected 2 clients, but found %d", len(defaultHub.loadClients()))
 	}
 	// Destroy it and ensure it's actually removed from the array
 	c.Destroy()
-	if len(clients) != 1 {
-		t.Errorf("Expected 1 client, but found %d", len(clients))
+	if len(defaultHub.loadClients()) != 1 {
+		t.Errorf("Expected 1 client, but found %d", len(defaultHub.loadClients()))
+	}
+}
+
+func TestCreateClientWithOptions(t *testing.T) {
+	c := CreateClientWithOptions(ClientOptions{
+		BufferSize:   4,
+		Level:        LWarn,
+		Namespaces:   []string{"options-ns"},
+		Overflow:     OverflowDropNewest,
+		BlockTimeout: time.Second,
+		SampleRate:   3,
+	})
+	defer c.Destroy()
+
+	if cap(c.writer) != 4 {
+		t.Errorf("buffer capacity = %d, want 4", cap(c.writer))
+	}
+	if c.GetLogLevel() != LWarn {
+		t.Errorf("level = %v, want WARN", c.GetLogLevel())
+	}
+	cfg := c.config.Load()
+	if cfg.overflow != OverflowDropNewest || cfg.blockTimeout != time.Second || cfg.sampleRate != 3 {
+		t.Errorf("overflow settings = %v/%v/%d, want drop-newest/1s/3", cfg.overflow, cfg.blockTimeout, cfg.sampleRate)
+	}
+
+	l := NewLogger("options-ns")
+	l.Info("filtered by level")
+	for i := 0; i < 6; i++ {
+		l.Warn(strconv.Itoa(i))
+	}
+	if c.Dropped() != 2 {
+		t.Errorf("Dropped() = %d, want 2", c.Dropped())
+	}
+	if e := c.Get(); e.Output != "0" {
+		t.Errorf("first entry = %q, want %q", e.Output, "0")
+	}
+}
+
+func TestCreateClientDefaultBufferSize(t *testing.T) {
+	c := CreateClientWithOptions(ClientOptions{})
+	defer c.Destroy()
+	if cap(c.writer) != DefaultBufferSize {
+		t.Errorf("buffer capacity = %d, want %d", cap(c.writer), DefaultBufferSize)
 	}
 }
 
@@ -310,9 +353,8 @@ func TestPanicln(t *testing.T) {
 	Panicln("panic line")
 }
 
-// TestLogLevelFiltering verifies that the client's log level is stored correctly.
-// Note: level filtering only applies to stderr output, not to client channels.
-// All entries matching the namespace are delivered to the client channel regardless of level.
+// TestLogLevelFiltering verifies that entries below the client's log level
+// are filtered out during dispatch and never reach the client's channel.
 func TestLogLevelFiltering(t *testing.T) {
 	c := CreateClient(DefaultNamespace)
 	c.SetLogLevel(LWarn)
@@ -321,24 +363,20 @@ func TestLogLevelFiltering(t *testing.T) {
 		t.Errorf("expected log level LWarn, got %d", c.GetLogLevel())
 	}
 
-	// Both entries arrive at the client channel (level filtering is stderr-only)
+	Trace("trace message")
+	Debug("debug message")
 	Info("info message")
 	Warn("warn message")
 
-	e1, ok := getEntry(c, time.Second)
+	e, ok := getEntry(c, time.Second)
 	if !ok {
-		t.Fatal("timed out waiting for first entry")
+		t.Fatal("timed out waiting for warn entry")
 	}
-	if e1.Output != "info message" {
-		t.Errorf("expected 'info message', got %q", e1.Output)
+	if e.Output != "warn message" {
+		t.Errorf("expected 'warn message', got %q", e.Output)
 	}
-
-	e2, ok := getEntry(c, time.Second)
-	if !ok {
-		t.Fatal("timed out waiting for second entry")
-	}
-	if e2.Output != "warn message" {
-		t.Errorf("expected 'warn message', got %q", e2.Output)
+	if n := len(c.writer); n != 0 {
+		t.Errorf("expected no buffered entries below LWarn, found %d", n)
 	}
 	c.Destroy()
 }
@@ -449,11 +487,12 @@ func TestNewLoggerEmptyNamespace(t *testing.T) {
 	}
 }
 
-// TestFileInfo verifies fileInfo returns a non-empty file:line string.
+// TestFileInfo verifies setCaller records a non-empty file:line string.
 func TestFileInfo(t *testing.T) {
-	fi := fileInfo(1)
-	if fi == "" || fi == "<???>:1" {
-		t.Errorf("fileInfo returned unexpected value: %q", fi)
+	var e Entry
+	NewHub().setCaller(&e, CallerDefault, 1)
+	if e.File == "" || e.File == "<???>:1" {
+		t.Errorf("setCaller recorded unexpected file: %q", e.File)
 	}
 }
 
@@ -616,3 +655,29 @@ func TestLevelString(t *testing.T) {
 func TestFlush(t *testing.T) {
 	defer Flush()
 }
+
+func TestFlushKeepsStderrSink(t *testing.T) {
+	Flush()
+	Flush()
+	if err := SetSinkLevel(StderrSink(), LTrace); err != nil {
+		t.Errorf("stderr sink after Flush: %v", err)
+	}
+}
+
+func TestFatalExitFunc(t *testing.T) {
+	var code int
+	SetExitFunc(func(c int) { code = c })
+	defer SetExitFunc(nil)
+	c := CreateClient(DefaultNamespace)
+	defer c.Destroy()
+	c.SetLogLevel(LFatal)
+
+	Fatal("fatal message")
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	e, ok := getEntry(c, time.Second)
+	if !ok || e.Level != "FATAL" {
+		t.Errorf("entry = %+v, %v, want the FATAL entry", e, ok)
+	}
+}
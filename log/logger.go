@@ -3,7 +3,7 @@ package log
 import (
 	"errors"
 	"fmt"
-	"os"
+	"maps"
 	"time"
 )
 
@@ -11,267 +11,435 @@ func Default() *Logger {
 	return &Logger{FileInfoDepth: 0, Namespace: DefaultNamespace}
 }
 
-func NewLogger(namespace string) *Logger {
+// With returns a Logger in the default namespace that attaches the given
+// key/value pairs to every entry. See [Logger.With].
+func With(kv ...any) *Logger {
+	return Default().With(kv...)
+}
+
+// LoggerOption configures a [Logger] created by [NewLogger].
+type LoggerOption func(*Logger)
+
+// WithLevel sets the minimum level the Logger emits. Calls below this level
+// return before their arguments are formatted.
+func WithLevel(level Level) LoggerOption {
+	return func(l *Logger) {
+		l.level = level
+	}
+}
+
+// WithHub makes the Logger send its entries to h instead of the default
+// Hub.
+func WithHub(h *Hub) LoggerOption {
+	return func(l *Logger) {
+		l.hub = h
+	}
+}
+
+func NewLogger(namespace string, opts ...LoggerOption) *Logger {
 	if namespace == "" {
 		namespace = DefaultNamespace
 	}
-	return &Logger{FileInfoDepth: 0, Namespace: namespace}
+	l := &Logger{FileInfoDepth: 0, Namespace: namespace}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
 }
 
 func (l *Logger) SetInfoDepth(depth int) {
 	l.FileInfoDepth = depth
 }
 
+// SetLevel sets the minimum level the Logger emits. Entries below level are
+// discarded at the call site, before formatting or dispatch to any client.
+// Panic and Fatal methods still panic and exit when their level is disabled.
+func (l *Logger) SetLevel(level Level) {
+	l.level = level
+}
+
+// GetLevel returns the minimum level the Logger emits.
+func (l *Logger) GetLevel() Level {
+	return l.level
+}
+
+// getHub returns the Hub the Logger sends entries to.
+func (l Logger) getHub() *Hub {
+	if l.hub == nil {
+		return defaultHub
+	}
+	return l.hub
+}
+
+// enabled reports whether an entry at level should be produced: it is at
+// or above the Logger's level and the Hub's level for its namespace, and not
+// dropped by its rate limit.
+func (l Logger) enabled(level Level) bool {
+	if level < l.level || level < l.getHub().levelFor(l.Namespace) {
+		return false
+	}
+	if l.limiter != nil {
+		if ok, reason := l.limiter.allow(level); !ok {
+			l.getHub().recordDrop(l.Namespace, reason)
+			return false
+		}
+	}
+	return true
+}
+
+// With returns a child Logger that attaches the given key/value pairs to
+// every entry it produces, in addition to any fields already carried by l.
+// Keys that are not strings are converted with fmt.Sprint, and a trailing
+// value without a key is recorded under "!BADKEY".
+func (l *Logger) With(kv ...any) *Logger {
+	child := *l
+	child.fields = mergeFields(l.fields, kv)
+	return &child
+}
+
+// Named returns a child Logger whose namespace is l's namespace and child
+// joined by a dot, e.g. "payments" becomes "payments.stripe". Children of
+// the default namespace are named child alone. Subscribe to a whole subtree
+// with a pattern such as "payments.*".
+func (l *Logger) Named(child string) *Logger {
+	named := *l
+	switch {
+	case child == "":
+	case l.Namespace == "" || l.Namespace == DefaultNamespace:
+		named.Namespace = child
+	default:
+		named.Namespace = l.Namespace + "." + child
+	}
+	return &named
+}
+
+// Fields returns a copy of the fields attached to the Logger.
+func (l *Logger) Fields() map[string]any {
+	return maps.Clone(l.fields)
+}
+
 // Trace prints out logs on trace level
 func (l Logger) Trace(args ...any) {
+	if !l.enabled(LTrace) {
+		return
+	}
 	output := fmt.Sprint(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "TRACE",
 		level:     LTrace,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Formatted print for Trace
 func (l Logger) Tracef(format string, args ...any) {
+	if !l.enabled(LTrace) {
+		return
+	}
 	output := fmt.Sprintf(format, args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "TRACE",
 		level:     LTrace,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Trace prints out logs on trace level with newline
 func (l Logger) Traceln(args ...any) {
+	if !l.enabled(LTrace) {
+		return
+	}
 	output := fmt.Sprintln(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "TRACE",
 		level:     LTrace,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Debug prints out logs on debug level
 func (l Logger) Debug(args ...any) {
+	if !l.enabled(LDebug) {
+		return
+	}
 	output := fmt.Sprint(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "DEBUG",
 		level:     LDebug,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Formatted print for Debug
 func (l Logger) Debugf(format string, args ...any) {
+	if !l.enabled(LDebug) {
+		return
+	}
 	output := fmt.Sprintf(format, args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "DEBUG",
 		level:     LDebug,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Debugln prints out logs on debug level with a newline
 func (l Logger) Debugln(args ...any) {
+	if !l.enabled(LDebug) {
+		return
+	}
 	output := fmt.Sprintln(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "DEBUG",
 		level:     LDebug,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Info prints out logs on info level
 func (l Logger) Info(args ...any) {
+	if !l.enabled(LInfo) {
+		return
+	}
 	output := fmt.Sprint(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "INFO",
 		level:     LInfo,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Formatted print for Info
 func (l Logger) Infof(format string, args ...any) {
+	if !l.enabled(LInfo) {
+		return
+	}
 	output := fmt.Sprintf(format, args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "INFO",
 		level:     LInfo,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Info prints out logs on info level with newline
 func (l Logger) Infoln(args ...any) {
+	if !l.enabled(LInfo) {
+		return
+	}
 	output := fmt.Sprintln(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "INFO",
 		level:     LInfo,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Notice prints out logs on notice level
 func (l Logger) Notice(args ...any) {
+	if !l.enabled(LNotice) {
+		return
+	}
 	output := fmt.Sprint(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "NOTICE",
 		level:     LNotice,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Formatted print for Notice
 func (l Logger) Noticef(format string, args ...any) {
+	if !l.enabled(LNotice) {
+		return
+	}
 	output := fmt.Sprintf(format, args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "NOTICE",
 		level:     LNotice,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Notice prints out logs on notice level with newline
 func (l Logger) Noticeln(args ...any) {
+	if !l.enabled(LNotice) {
+		return
+	}
 	output := fmt.Sprintln(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "NOTICE",
 		level:     LNotice,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Warn prints out logs on warn level
 func (l Logger) Warn(args ...any) {
+	if !l.enabled(LWarn) {
+		return
+	}
 	output := fmt.Sprint(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "WARN",
 		level:     LWarn,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Formatted print for Warn
 func (l Logger) Warnf(format string, args ...any) {
+	if !l.enabled(LWarn) {
+		return
+	}
 	output := fmt.Sprintf(format, args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "WARN",
 		level:     LWarn,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Warn prints out logs on warn level with a newline
 func (l Logger) Warnln(args ...any) {
+	if !l.enabled(LWarn) {
+		return
+	}
 	output := fmt.Sprintln(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "WARN",
 		level:     LWarn,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Error prints out logs on error level
 func (l Logger) Error(args ...any) {
+	if !l.enabled(LError) {
+		return
+	}
 	output := fmt.Sprint(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "ERROR",
 		level:     LError,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Formatted print for error
 func (l Logger) Errorf(format string, args ...any) {
+	if !l.enabled(LError) {
+		return
+	}
 	output := fmt.Sprintf(format, args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "ERROR",
 		level:     LError,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Error prints out logs on error level with a new line
 func (l Logger) Errorln(args ...any) {
+	if !l.enabled(LError) {
+		return
+	}
 	output := fmt.Sprintln(args...)
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "ERROR",
 		level:     LError,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	l.setCaller(&e, 2+l.FileInfoDepth)
+	l.getHub().createLog(e)
 }
 
 // Panic prints out logs on panic level
@@ -280,12 +448,16 @@ func (l Logger) Panic(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "PANIC",
 		level:     LPanic,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
+	if l.enabled(LPanic) {
+		l.setCaller(&e, 2+l.FileInfoDepth)
+		l.getHub().createLog(e)
+	}
+	l.getHub().Flush()
 	if len(args) > 0 {
 		switch args[0].(type) {
 		case error:
@@ -294,7 +466,6 @@ func (l Logger) Panic(args ...any) {
 			// falls through to default below
 		}
 	}
-	Flush()
 	panic(errors.New(output))
 }
 
@@ -304,12 +475,16 @@ func (l Logger) Panicf(format string, args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "PANIC",
 		level:     LPanic,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
+	}
+	if l.enabled(LPanic) {
+		l.setCaller(&e, 2+l.FileInfoDepth)
+		l.getHub().createLog(e)
 	}
-	createLog(e)
+	l.getHub().Flush()
 	if len(args) > 0 {
 		switch args[0].(type) {
 		case error:
@@ -318,7 +493,6 @@ func (l Logger) Panicf(format string, args ...any) {
 			// falls through to default below
 		}
 	}
-	Flush()
 	panic(errors.New(output))
 }
 
@@ -328,12 +502,16 @@ func (l Logger) Panicln(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "PANIC",
 		level:     LPanic,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
+	}
+	if l.enabled(LPanic) {
+		l.setCaller(&e, 2+l.FileInfoDepth)
+		l.getHub().createLog(e)
 	}
-	createLog(e)
+	l.getHub().Flush()
 	if len(args) > 0 {
 		switch args[0].(type) {
 		case error:
@@ -342,7 +520,6 @@ func (l Logger) Panicln(args ...any) {
 			// falls through to default below
 		}
 	}
-	Flush()
 	panic(errors.New(output))
 }
 
@@ -352,14 +529,16 @@ func (l Logger) Fatal(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "FATAL",
 		level:     LFatal,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
 	}
-	createLog(e)
-	Flush()
-	os.Exit(1)
+	if l.enabled(LFatal) {
+		l.setCaller(&e, 2+l.FileInfoDepth)
+		l.getHub().createLog(e)
+	}
+	l.getHub().exit(1)
 }
 
 // Formatted print for fatal
@@ -368,14 +547,16 @@ func (l Logger) Fatalf(format string, args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "FATAL",
 		level:     LFatal,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
+	}
+	if l.enabled(LFatal) {
+		l.setCaller(&e, 2+l.FileInfoDepth)
+		l.getHub().createLog(e)
 	}
-	createLog(e)
-	Flush()
-	os.Exit(1)
+	l.getHub().exit(1)
 }
 
 // Fatal prints fatal level with a new line
@@ -384,14 +565,16 @@ func (l Logger) Fatalln(args ...any) {
 	e := Entry{
 		Timestamp: time.Now(),
 		Output:    output,
-		File:      fileInfo(2 + l.FileInfoDepth),
 		Level:     "FATAL",
 		level:     LFatal,
 		Namespace: l.Namespace,
+		Fields:    l.fields,
+	}
+	if l.enabled(LFatal) {
+		l.setCaller(&e, 2+l.FileInfoDepth)
+		l.getHub().createLog(e)
 	}
-	createLog(e)
-	Flush()
-	os.Exit(1)
+	l.getHub().exit(1)
 }
 
 // Handles print to info
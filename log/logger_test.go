@@ -516,3 +516,145 @@ func TestLoggerPanicWithError(t *testing.T) {
 type errTest string
 
 func (e errTest) Error() string { return string(e) }
+
+func TestLoggerWith(t *testing.T) {
+	c := CreateClient("logger-with")
+	c.SetLogLevel(LTrace)
+
+	parent := NewLogger("logger-with").With("request_id", "abc123")
+	child := parent.With("user_id", 42)
+	child.Info("with fields")
+
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.Fields["request_id"] != "abc123" {
+		t.Errorf("request_id = %v, want abc123", e.Fields["request_id"])
+	}
+	if e.Fields["user_id"] != 42 {
+		t.Errorf("user_id = %v, want 42", e.Fields["user_id"])
+	}
+	if _, ok := parent.Fields()["user_id"]; ok {
+		t.Error("With should not modify the parent logger's fields")
+	}
+	c.Destroy()
+}
+
+func TestLoggerWithBadKey(t *testing.T) {
+	l := NewLogger("logger-with-badkey").With(1, "one", "dangling")
+	fields := l.Fields()
+	if fields["1"] != "one" {
+		t.Errorf("fields[1] = %v, want one", fields["1"])
+	}
+	if fields[badKey] != "dangling" {
+		t.Errorf("fields[%s] = %v, want dangling", badKey, fields[badKey])
+	}
+}
+
+func TestFormatFields(t *testing.T) {
+	got := formatFields(map[string]any{"b": 2, "a": "x y", "c": ""})
+	want := `a="x y" b=2 c=""`
+	if got != want {
+		t.Errorf("formatFields = %q, want %q", got, want)
+	}
+	if formatFields(nil) != "" {
+		t.Error("formatFields(nil) should be empty")
+	}
+}
+
+func TestLoggerSetLevel(t *testing.T) {
+	c := CreateClient("logger-level")
+	c.SetLogLevel(LTrace)
+
+	l := NewLogger("logger-level", WithLevel(LWarn))
+	if l.GetLevel() != LWarn {
+		t.Errorf("GetLevel() = %v, want WARN", l.GetLevel())
+	}
+	l.Debug("should be dropped")
+	l.Info("should be dropped")
+	l.Warn("should arrive")
+
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.Output != "should arrive" {
+		t.Errorf("output = %q, want %q", e.Output, "should arrive")
+	}
+
+	l.SetLevel(LTrace)
+	l.Trace("trace after SetLevel")
+	e, ok = getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.Output != "trace after SetLevel" {
+		t.Errorf("output = %q, want %q", e.Output, "trace after SetLevel")
+	}
+	c.Destroy()
+}
+
+// stringer counts how many times it is formatted.
+type stringer struct{ calls *int }
+
+func (s stringer) String() string {
+	*s.calls++
+	return "formatted"
+}
+
+func TestLoggerDisabledLevelSkipsFormatting(t *testing.T) {
+	calls := 0
+	l := NewLogger("logger-level-skip", WithLevel(LError))
+	l.Debug(stringer{&calls})
+	l.Infof("%v", stringer{&calls})
+	l.Warnln(stringer{&calls})
+	if calls != 0 {
+		t.Errorf("disabled calls formatted their arguments %d times", calls)
+	}
+}
+
+func TestLoggerPanicBelowLevelStillPanics(t *testing.T) {
+	l := NewLogger("logger-level-panic", WithLevel(LFatal))
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic, got nil")
+		}
+	}()
+	l.Panic("still panics")
+}
+
+func TestLoggerFatalExitFunc(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	s := &memorySink{}
+	h.AddSink(s, LTrace)
+	var code int
+	h.SetExitFunc(func(c int) { code = c })
+
+	h.NewLogger("logger-fatal").Fatalf("fatal %d", 1)
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if got := s.outputs(); len(got) != 1 || got[0] != "fatal 1" {
+		t.Errorf("sink received %v before exit, want [fatal 1]", got)
+	}
+}
+
+func TestLoggerLogsAfterPanic(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	s := &memorySink{}
+	h.AddSink(s, LTrace)
+	l := h.NewLogger("logger-after-panic")
+
+	func() {
+		defer func() { recover() }()
+		l.Panic("recovered")
+	}()
+	l.Info("still logging")
+	h.Flush()
+	if got := s.outputs(); len(got) != 2 || got[1] != "still logging" {
+		t.Errorf("sink received %v, want the entry logged after the panic", got)
+	}
+}
@@ -0,0 +1,69 @@
+package log
+
+import "strings"
+
+// namespaceFilter is a compiled list of namespace patterns. The zero value
+// matches every namespace.
+type namespaceFilter struct {
+	include []string
+	exclude []string
+}
+
+func newNamespaceFilter(patterns []string) namespaceFilter {
+	var f namespaceFilter
+	for _, p := range patterns {
+		if ex, ok := strings.CutPrefix(p, "!"); ok {
+			f.exclude = append(f.exclude, ex)
+		} else {
+			f.include = append(f.include, p)
+		}
+	}
+	return f
+}
+
+// match reports whether namespace matches an include pattern, or there are
+// none, and matches no exclude pattern.
+func (f namespaceFilter) match(namespace string) bool {
+	for _, p := range f.exclude {
+		if globMatch(p, namespace) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, p := range f.include {
+		if globMatch(p, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch reports whether s matches pattern, where * matches any run of
+// characters, including none. All other characters match themselves.
+func globMatch(pattern, s string) bool {
+	star := strings.IndexByte(pattern, '*')
+	if star < 0 {
+		return pattern == s
+	}
+	// The literal prefix must match exactly.
+	if !strings.HasPrefix(s, pattern[:star]) {
+		return false
+	}
+	s = s[star:]
+	pattern = pattern[star+1:]
+	for {
+		star = strings.IndexByte(pattern, '*')
+		if star < 0 {
+			// The last literal segment must end the string.
+			return strings.HasSuffix(s, pattern)
+		}
+		i := strings.Index(s, pattern[:star])
+		if i < 0 {
+			return false
+		}
+		s = s[i+star:]
+		pattern = pattern[star+1:]
+	}
+}
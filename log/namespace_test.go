@@ -0,0 +1,97 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"payments", "payments", true},
+		{"payments", "payments.stripe", false},
+		{"payments.*", "payments.stripe", true},
+		{"payments.*", "payments.stripe.webhooks", true},
+		{"payments.*", "payments", false},
+		{"*.db", "orders.db", true},
+		{"*.db", "orders.dbx", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"ab*ba", "aba", false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestNamespaceFilter(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		ns       string
+		want     bool
+	}{
+		{nil, "anything", true},
+		{[]string{"api"}, "api", true},
+		{[]string{"api"}, "db", false},
+		{[]string{"!healthcheck"}, "api", true},
+		{[]string{"!healthcheck"}, "healthcheck", false},
+		{[]string{"payments.*", "!payments.health"}, "payments.ledger", true},
+		{[]string{"payments.*", "!payments.health"}, "payments.health", false},
+		{[]string{"payments.*", "!payments.health"}, "orders", false},
+		{[]string{"!*.debug"}, "api.debug", false},
+	}
+	for _, tt := range tests {
+		if got := newNamespaceFilter(tt.patterns).match(tt.ns); got != tt.want {
+			t.Errorf("filter %v match(%q) = %v, want %v", tt.patterns, tt.ns, got, tt.want)
+		}
+	}
+}
+
+func TestNamed(t *testing.T) {
+	payments := NewLogger("payments")
+	if ns := payments.Named("stripe").Namespace; ns != "payments.stripe" {
+		t.Errorf("Named = %q, want payments.stripe", ns)
+	}
+	if ns := payments.Named("stripe").Named("webhooks").Namespace; ns != "payments.stripe.webhooks" {
+		t.Errorf("nested Named = %q", ns)
+	}
+	if ns := Default().Named("api").Namespace; ns != "api" {
+		t.Errorf("Named on default = %q, want api", ns)
+	}
+	if ns := payments.Named("").Namespace; ns != "payments" {
+		t.Errorf("Named with empty child = %q", ns)
+	}
+	if payments.Namespace != "payments" {
+		t.Error("Named modified the parent")
+	}
+}
+
+func TestWildcardClient(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient("payments.*", "!payments.health")
+	defer c.Destroy()
+	payments := h.NewLogger("payments")
+	payments.Named("health").Info("ok")
+	payments.Info("root")
+	h.NewLogger("orders").Info("other")
+	payments.Named("stripe").Info("charged")
+
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.Namespace != "payments.stripe" {
+		t.Errorf("namespace = %q, want payments.stripe", e.Namespace)
+	}
+	if len(c.writer) != 0 {
+		t.Errorf("unexpected extra entries: %d", len(c.writer))
+	}
+}
@@ -0,0 +1,140 @@
+package log
+
+import (
+	"errors"
+	"time"
+)
+
+// OverflowPolicy decides what happens to a new entry when a client's buffer
+// is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropOldest evicts the oldest queued entry to make room for
+	// the new one. This is the default.
+	OverflowDropOldest OverflowPolicy = iota
+	// OverflowDropNewest discards the new entry and keeps the queue intact.
+	OverflowDropNewest
+	// OverflowBlock waits up to the client's block timeout for room in the
+	// buffer, then discards the new entry. The logging goroutine is blocked
+	// while waiting.
+	OverflowBlock
+	// OverflowSample keeps one in every N entries that arrive while the
+	// buffer is full, evicting the oldest queued entry for each one kept.
+	OverflowSample
+)
+
+const (
+	// DefaultBlockTimeout is used by [OverflowBlock] when no timeout is set.
+	DefaultBlockTimeout = 100 * time.Millisecond
+	// DefaultSampleRate is used by [OverflowSample] when no rate is set.
+	DefaultSampleRate = 10
+)
+
+// String returns the name of the policy.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowBlock:
+		return "block"
+	case OverflowSample:
+		return "sample"
+	default:
+		return "unknown"
+	}
+}
+
+// SetOverflowPolicy sets what the client does with new entries when its
+// buffer is full.
+func (c *Client) SetOverflowPolicy(policy OverflowPolicy) {
+	if !c.initialized.Load() {
+		panic(errors.New("cannot set overflow policy for uninitialized client, use CreateClient instead"))
+	}
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.overflow = policy
+	})
+}
+
+// SetBlockTimeout sets how long [OverflowBlock] waits for room in the
+// buffer. A zero or negative timeout uses [DefaultBlockTimeout].
+func (c *Client) SetBlockTimeout(timeout time.Duration) {
+	if !c.initialized.Load() {
+		panic(errors.New("cannot set block timeout for uninitialized client, use CreateClient instead"))
+	}
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.blockTimeout = timeout
+	})
+}
+
+// SetSampleRate sets N for [OverflowSample], keeping one in every N
+// overflowing entries. A rate below 1 uses [DefaultSampleRate].
+func (c *Client) SetSampleRate(n int) {
+	if !c.initialized.Load() {
+		panic(errors.New("cannot set sample rate for uninitialized client, use CreateClient instead"))
+	}
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.sampleRate = n
+	})
+}
+
+// Dropped returns how many entries the client has lost because its buffer
+// was full, whether evicted from the queue or discarded on arrival.
+func (c *Client) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+// deliver queues e for the client, applying its overflow policy when the
+// buffer is full.
+func (c *Client) deliver(e Entry, cfg *clientConfig) {
+	select {
+	case c.writer <- e:
+		return
+	default:
+	}
+	switch cfg.overflow {
+	case OverflowDropNewest:
+		c.dropped.Add(1)
+	case OverflowBlock:
+		timeout := cfg.blockTimeout
+		if timeout <= 0 {
+			timeout = DefaultBlockTimeout
+		}
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case c.writer <- e:
+		case <-timer.C:
+			c.dropped.Add(1)
+		}
+	case OverflowSample:
+		rate := cfg.sampleRate
+		if rate < 1 {
+			rate = DefaultSampleRate
+		}
+		if c.overflowed.Add(1)%uint64(rate) != 0 {
+			c.dropped.Add(1)
+			return
+		}
+		c.evictAndSend(e)
+	default:
+		c.evictAndSend(e)
+	}
+}
+
+// evictAndSend makes room by discarding the oldest queued entry, then
+// queues e.
+func (c *Client) evictAndSend(e Entry) {
+	select {
+	case <-c.writer:
+		c.dropped.Add(1)
+	default:
+	}
+	select {
+	case c.writer <- e:
+	default:
+		c.dropped.Add(1)
+	}
+}
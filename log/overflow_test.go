@@ -0,0 +1,110 @@
+package log
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fillClient logs n entries numbered from 0 into namespace ns.
+func fillClient(ns string, n int) {
+	l := NewLogger(ns)
+	for i := 0; i < n; i++ {
+		l.Info(strconv.Itoa(i))
+	}
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	c := CreateClient("overflow-oldest")
+	defer c.Destroy()
+
+	fillClient("overflow-oldest", cap(c.writer)+10)
+	if got := c.Dropped(); got != 10 {
+		t.Errorf("Dropped() = %d, want 10", got)
+	}
+	if e := c.Get(); e.Output != "10" {
+		t.Errorf("first queued entry = %q, want %q", e.Output, "10")
+	}
+}
+
+func TestOverflowDropNewest(t *testing.T) {
+	c := CreateClient("overflow-newest")
+	defer c.Destroy()
+	c.SetOverflowPolicy(OverflowDropNewest)
+
+	fillClient("overflow-newest", cap(c.writer)+10)
+	if got := c.Dropped(); got != 10 {
+		t.Errorf("Dropped() = %d, want 10", got)
+	}
+	if e := c.Get(); e.Output != "0" {
+		t.Errorf("first queued entry = %q, want %q", e.Output, "0")
+	}
+}
+
+func TestOverflowBlock(t *testing.T) {
+	c := CreateClient("overflow-block")
+	defer c.Destroy()
+	c.SetOverflowPolicy(OverflowBlock)
+	c.SetBlockTimeout(20 * time.Millisecond)
+
+	fillClient("overflow-block", cap(c.writer))
+	start := time.Now()
+	NewLogger("overflow-block").Info("times out")
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("blocked for %v, want at least 20ms", elapsed)
+	}
+	if got := c.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+
+	// With a reader making room, a blocked entry is delivered.
+	c.SetBlockTimeout(time.Second)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		c.Get()
+	}()
+	NewLogger("overflow-block").Info("delivered")
+	if got := c.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d after reader made room, want 1", got)
+	}
+}
+
+func TestOverflowSample(t *testing.T) {
+	c := CreateClient("overflow-sample")
+	defer c.Destroy()
+	c.SetOverflowPolicy(OverflowSample)
+	c.SetSampleRate(5)
+
+	fillClient("overflow-sample", cap(c.writer)+20)
+	// 16 overflowing entries are discarded and 4 are kept, each evicting
+	// one queued entry.
+	if got := c.Dropped(); got != 20 {
+		t.Errorf("Dropped() = %d, want 20", got)
+	}
+	var last Entry
+	for len(c.writer) > 0 {
+		last = c.Get()
+	}
+	want := strconv.Itoa(cap(c.writer) + 19)
+	if last.Output != want {
+		t.Errorf("last queued entry = %q, want %q", last.Output, want)
+	}
+}
+
+func TestOverflowPolicyString(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		want   string
+	}{
+		{OverflowDropOldest, "drop-oldest"},
+		{OverflowDropNewest, "drop-newest"},
+		{OverflowBlock, "block"},
+		{OverflowSample, "sample"},
+		{OverflowPolicy(99), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.String(); got != tt.want {
+			t.Errorf("OverflowPolicy(%d).String() = %q, want %q", tt.policy, got, tt.want)
+		}
+	}
+}
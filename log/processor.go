@@ -0,0 +1,134 @@
+package log
+
+import (
+	"maps"
+	"os"
+	"runtime/debug"
+)
+
+// Processor inspects and may modify an entry before it is delivered to any
+// client or sink. Returning false drops the entry. Processors run once per
+// entry, in registration order, on the logging goroutine, so they should be
+// fast. The entry's Fields map is a private copy that may be modified in
+// place; use [Entry.SetField] to add a field.
+type Processor func(e *Entry) (keep bool)
+
+type namedProcessor struct {
+	name string
+	fn   Processor
+}
+
+// SetField sets a field on e, creating the Fields map if needed.
+func (e *Entry) SetField(key string, value any) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]any)
+	}
+	e.Fields[key] = value
+}
+
+// RegisterProcessor adds p to the default Hub under name. See
+// [Hub.RegisterProcessor].
+func RegisterProcessor(name string, p Processor) {
+	defaultHub.RegisterProcessor(name, p)
+}
+
+// RegisterProcessor appends p to the chain run on every entry logged on h,
+// after level filtering and rate limiting and before duplicate suppression
+// and fan-out. Registering a name again replaces its processor in place; a
+// nil p removes it.
+func (h *Hub) RegisterProcessor(name string, p Processor) {
+	h.processorsMux.Lock()
+	defer h.processorsMux.Unlock()
+	var next []namedProcessor
+	replaced := false
+	for _, x := range h.loadProcessors() {
+		if x.name == name {
+			replaced = true
+			if p == nil {
+				continue
+			}
+			x.fn = p
+		}
+		next = append(next, x)
+	}
+	if !replaced && p != nil {
+		next = append(next, namedProcessor{name: name, fn: p})
+	}
+	h.processors.Store(&next)
+}
+
+func (h *Hub) loadProcessors() []namedProcessor {
+	if p := h.processors.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// process runs the processor chain on e and reports whether to keep it.
+func (h *Hub) process(e *Entry) bool {
+	processors := h.loadProcessors()
+	if len(processors) == 0 {
+		return true
+	}
+	// Fields may be shared with the Logger that produced the entry.
+	e.Fields = maps.Clone(e.Fields)
+	for _, p := range processors {
+		if !p.fn(e) {
+			return false
+		}
+	}
+	if e.Level != e.level.String() {
+		e.level = parseLevelString(e.Level)
+	}
+	return true
+}
+
+// HostnameEnricher returns a Processor that adds a "host" field with the
+// machine's hostname.
+func HostnameEnricher() Processor {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return func(e *Entry) bool {
+		e.SetField("host", host)
+		return true
+	}
+}
+
+// PIDEnricher returns a Processor that adds a "pid" field with the process
+// ID.
+func PIDEnricher() Processor {
+	pid := os.Getpid()
+	return func(e *Entry) bool {
+		e.SetField("pid", pid)
+		return true
+	}
+}
+
+// BuildVersionEnricher returns a Processor that adds a "version" field. If
+// version is empty, the main module's version from the build info is used,
+// followed by the VCS revision when the binary was built from a checkout.
+func BuildVersionEnricher(version string) Processor {
+	if version == "" {
+		version = buildVersion()
+	}
+	return func(e *Entry) bool {
+		e.SetField("version", version)
+		return true
+	}
+}
+
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && s.Value != "" {
+			version += "+" + s.Value
+		}
+	}
+	return version
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessorChain(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+
+	var order []string
+	h.RegisterProcessor("first", func(e *Entry) bool {
+		order = append(order, "first")
+		e.SetField("stage", 1)
+		return true
+	})
+	h.RegisterProcessor("second", func(e *Entry) bool {
+		order = append(order, "second")
+		e.Fields["stage"] = e.Fields["stage"].(int) + 1
+		return !strings.HasPrefix(e.Output, "healthcheck")
+	})
+	h.RegisterProcessor("escalate", func(e *Entry) bool {
+		if strings.Contains(e.Output, "timeout") {
+			e.Level = "ERROR"
+		}
+		return true
+	})
+
+	l := h.NewLogger("proc").With("request_id", "abc")
+	l.Info("healthcheck ok")
+	l.Warn("upstream timeout")
+
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.Output != "upstream timeout" {
+		t.Fatalf("output = %q, dropped entry was delivered", e.Output)
+	}
+	if e.Fields["stage"] != 2 || e.Fields["request_id"] != "abc" {
+		t.Errorf("fields = %v", e.Fields)
+	}
+	if e.Level != "ERROR" || e.level != LError {
+		t.Errorf("level = %s/%v, want ERROR", e.Level, e.level)
+	}
+	if want := []string{"first", "second", "first", "second"}; !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+	if _, ok := l.Fields()["stage"]; ok {
+		t.Error("processor modified the logger's fields")
+	}
+}
+
+func TestRegisterProcessorReplaceRemove(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+	h.RegisterProcessor("a", func(e *Entry) bool { e.Output += "a"; return true })
+	h.RegisterProcessor("b", func(e *Entry) bool { e.Output += "b"; return true })
+	h.RegisterProcessor("a", func(e *Entry) bool { e.Output += "A"; return true })
+	h.NewLogger("proc").Info("")
+	h.RegisterProcessor("a", nil)
+	h.NewLogger("proc").Info("")
+	for _, want := range []string{"Ab", "b"} {
+		e, ok := getEntry(c, time.Second)
+		if !ok {
+			t.Fatal("timed out")
+		}
+		if e.Output != want {
+			t.Errorf("output = %q, want %q", e.Output, want)
+		}
+	}
+}
+
+func TestEnrichers(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+	h.RegisterProcessor("host", HostnameEnricher())
+	h.RegisterProcessor("pid", PIDEnricher())
+	h.RegisterProcessor("version", BuildVersionEnricher("v1.2.3"))
+	h.NewLogger("proc").Info("enriched")
+
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	host, _ := os.Hostname()
+	if e.Fields["host"] != host || e.Fields["pid"] != os.Getpid() || e.Fields["version"] != "v1.2.3" {
+		t.Errorf("fields = %v", e.Fields)
+	}
+	if BuildVersionEnricher("")(&e); e.Fields["version"] == "" {
+		t.Error("build version should fall back to the build info")
+	}
+}
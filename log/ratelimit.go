@@ -0,0 +1,135 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimit caps how many entries a namespace or Logger produces. Panic and
+// Fatal entries are never dropped.
+type RateLimit struct {
+	// PerSecond is the sustained number of entries allowed per second by
+	// a token bucket. Zero disables the bucket.
+	PerSecond float64
+	// Burst is the bucket size: how many entries may be logged at once
+	// before PerSecond applies. Defaults to PerSecond, and at least 1.
+	Burst int
+	// Sample keeps 1 in N entries of a level, e.g. {LDebug: 10}. Levels
+	// that are missing or have N of 1 or less are not sampled.
+	Sample map[Level]int
+}
+
+type dropReason int
+
+const (
+	dropSampled dropReason = iota + 1
+	dropLimited
+)
+
+// limiter applies a RateLimit. Sampling runs first, so sampled-out entries
+// do not consume tokens.
+type limiter struct {
+	rate   float64
+	burst  float64
+	sample [LFatal + 1]uint64
+	seen   [LFatal + 1]atomic.Uint64
+
+	mux    sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rl RateLimit) *limiter {
+	r := &limiter{rate: rl.PerSecond, burst: float64(rl.Burst)}
+	if r.burst <= 0 {
+		r.burst = max(r.rate, 1)
+	}
+	r.tokens = r.burst
+	r.last = time.Now()
+	for level, n := range rl.Sample {
+		if level >= LTrace && level <= LFatal && n > 1 {
+			r.sample[level] = uint64(n)
+		}
+	}
+	return r
+}
+
+// allow reports whether an entry at level may be logged, and if not, why.
+func (r *limiter) allow(level Level) (bool, dropReason) {
+	if level < LTrace || level >= LPanic {
+		return true, 0
+	}
+	if n := r.sample[level]; n > 1 && (r.seen[level].Add(1)-1)%n != 0 {
+		return false, dropSampled
+	}
+	if r.rate <= 0 {
+		return true, 0
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	now := time.Now()
+	r.tokens = min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+	if r.tokens < 1 {
+		return false, dropLimited
+	}
+	r.tokens--
+	return true, 0
+}
+
+// WithRateLimit applies rl to every entry the Logger and the loggers derived
+// from it produce, before the arguments are formatted. It is applied in
+// addition to any limit set for the namespace with [SetRateLimit].
+func WithRateLimit(rl RateLimit) LoggerOption {
+	return func(l *Logger) {
+		l.limiter = newLimiter(rl)
+	}
+}
+
+// SetRateLimit limits entries logged to namespace on the default Hub. See
+// [Hub.SetRateLimit].
+func SetRateLimit(namespace string, rl RateLimit) {
+	defaultHub.SetRateLimit(namespace, rl)
+}
+
+// SetRateLimit limits the entries logged to namespace on h, by any Logger.
+// Dropped entries are counted in the namespace's [NamespaceInfo]. The zero
+// RateLimit removes the limit.
+func (h *Hub) SetRateLimit(namespace string, rl RateLimit) {
+	h.rateLimitsMux.Lock()
+	defer h.rateLimitsMux.Unlock()
+	next := make(map[string]*limiter)
+	if p := h.rateLimits.Load(); p != nil {
+		for ns, r := range *p {
+			next[ns] = r
+		}
+	}
+	if rl.PerSecond <= 0 && len(rl.Sample) == 0 {
+		delete(next, namespace)
+	} else {
+		next[namespace] = newLimiter(rl)
+	}
+	if len(next) == 0 {
+		h.rateLimits.Store(nil)
+		return
+	}
+	h.rateLimits.Store(&next)
+}
+
+// rateLimited reports whether the namespace limit drops e, counting it if so.
+func (h *Hub) rateLimited(e Entry) bool {
+	p := h.rateLimits.Load()
+	if p == nil {
+		return false
+	}
+	r := (*p)[e.Namespace]
+	if r == nil {
+		return false
+	}
+	if ok, reason := r.allow(e.level); !ok {
+		h.recordDrop(e.Namespace, reason)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+// drain counts the entries queued for c.
+func drain(c *Client) int {
+	n := 0
+	for {
+		select {
+		case <-c.writer:
+			n++
+		default:
+			return n
+		}
+	}
+}
+
+func namespaceInfo(h *Hub, namespace string) NamespaceInfo {
+	for _, info := range h.GetNamespaceInfo() {
+		if info.Name == namespace {
+			return info
+		}
+	}
+	return NamespaceInfo{}
+}
+
+func TestLimiterSampling(t *testing.T) {
+	r := newLimiter(RateLimit{Sample: map[Level]int{LDebug: 10, LError: 1}})
+	kept := map[Level]int{}
+	for i := 0; i < 100; i++ {
+		for _, level := range []Level{LDebug, LError} {
+			if ok, _ := r.allow(level); ok {
+				kept[level]++
+			}
+		}
+	}
+	if kept[LDebug] != 10 || kept[LError] != 100 {
+		t.Errorf("kept %v, want 10 DEBUG and 100 ERROR", kept)
+	}
+}
+
+func TestLimiterTokenBucket(t *testing.T) {
+	r := newLimiter(RateLimit{PerSecond: 1, Burst: 5})
+	allowed := 0
+	for i := 0; i < 20; i++ {
+		if ok, reason := r.allow(LInfo); ok {
+			allowed++
+		} else if reason != dropLimited {
+			t.Errorf("reason = %v, want dropLimited", reason)
+		}
+	}
+	if allowed != 5 {
+		t.Errorf("allowed %d entries, want the burst of 5", allowed)
+	}
+	if ok, _ := r.allow(LPanic); !ok {
+		t.Error("panic entries should never be limited")
+	}
+	// Refill.
+	r.mux.Lock()
+	r.last = r.last.Add(-2 * time.Second)
+	r.mux.Unlock()
+	if ok, _ := r.allow(LInfo); !ok {
+		t.Error("bucket did not refill")
+	}
+}
+
+func TestSetRateLimit(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+	h.SetRateLimit("noisy", RateLimit{PerSecond: 1, Burst: 3, Sample: map[Level]int{LDebug: 4}})
+
+	noisy := h.NewLogger("noisy")
+	for i := 0; i < 10; i++ {
+		noisy.Warn("flapping")
+		noisy.Debug("chatty")
+	}
+	h.NewLogger("quiet").Warn("unaffected")
+
+	if n := drain(c); n != 4 {
+		t.Errorf("delivered %d entries, want 3 from noisy and 1 from quiet", n)
+	}
+	info := namespaceInfo(h, "noisy")
+	if info.Sampled != 7 {
+		t.Errorf("Sampled = %d, want 7", info.Sampled)
+	}
+	// 3 sampled-in DEBUG entries and 10 WARN compete for a burst of 3.
+	if info.RateLimited != 10 {
+		t.Errorf("RateLimited = %d, want 10", info.RateLimited)
+	}
+
+	h.SetRateLimit("noisy", RateLimit{})
+	for i := 0; i < 10; i++ {
+		noisy.Warn("flapping")
+	}
+	if n := drain(c); n != 10 {
+		t.Errorf("delivered %d entries after removing the limit, want 10", n)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	c := h.CreateClient()
+	defer c.Destroy()
+	l := h.NewLogger("sampled", WithRateLimit(RateLimit{Sample: map[Level]int{LDebug: 5}}))
+	child := l.With("k", "v")
+	for i := 0; i < 10; i++ {
+		l.Debug("a")
+		child.Debug("b")
+		l.Error("never sampled")
+	}
+	// l and child share one sampler: 20 DEBUG calls keep 4.
+	if n := drain(c); n != 14 {
+		t.Errorf("delivered %d entries, want 4 DEBUG and 10 ERROR", n)
+	}
+	if info := namespaceInfo(h, "sampled"); info.Sampled != 16 {
+		t.Errorf("Sampled = %d, want 16", info.Sampled)
+	}
+}
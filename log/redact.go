@@ -0,0 +1,167 @@
+package log
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+)
+
+// DefaultRedaction replaces redacted text when no replacement is set.
+const DefaultRedaction = "[REDACTED]"
+
+// RedactionConfig configures the redaction stage of a Hub. Built-in
+// detectors cover bearer tokens, AWS access key IDs and secret keys, email
+// addresses and credit card numbers (validated with the Luhn checksum).
+type RedactionConfig struct {
+	// DisableBuiltins turns off the built-in detectors, leaving only
+	// Patterns and Fields.
+	DisableBuiltins bool
+	// Patterns are additional expressions whose matches are redacted.
+	Patterns []*regexp.Regexp
+	// Fields lists field names, matched case-insensitively, whose values
+	// are always redacted whatever their type.
+	Fields []string
+	// Replacement is substituted for redacted text. Defaults to
+	// DefaultRedaction.
+	Replacement string
+}
+
+type detector struct {
+	re *regexp.Regexp
+	// valid, if set, filters out false positives.
+	valid func(match string) bool
+}
+
+var builtinDetectors = []detector{
+	{re: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)},
+	{re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{re: regexp.MustCompile(`(?i)\baws_secret_access_key\s*[:=]\s*[A-Za-z0-9/+=]{40}\b`)},
+	{re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	{re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhnValid},
+}
+
+// redactor is an immutable, compiled RedactionConfig.
+type redactor struct {
+	detectors   []detector
+	fields      map[string]bool
+	replacement string
+}
+
+// SetRedaction enables redaction on the default Hub. See
+// [Hub.SetRedaction].
+func SetRedaction(cfg *RedactionConfig) {
+	defaultHub.SetRedaction(cfg)
+}
+
+// SetRedaction scrubs secrets from the output and field values of every
+// entry logged on h, after processors run and before the entry reaches any
+// client, sink or the history. The number of redactions is counted per
+// namespace in [NamespaceInfo]. A nil cfg disables redaction.
+func (h *Hub) SetRedaction(cfg *RedactionConfig) {
+	if cfg == nil {
+		h.redactor.Store(nil)
+		return
+	}
+	r := &redactor{replacement: cfg.Replacement}
+	if r.replacement == "" {
+		r.replacement = DefaultRedaction
+	}
+	if !cfg.DisableBuiltins {
+		r.detectors = append(r.detectors, builtinDetectors...)
+	}
+	for _, re := range cfg.Patterns {
+		r.detectors = append(r.detectors, detector{re: re})
+	}
+	if len(cfg.Fields) > 0 {
+		r.fields = make(map[string]bool, len(cfg.Fields))
+		for _, f := range cfg.Fields {
+			r.fields[strings.ToLower(f)] = true
+		}
+	}
+	h.redactor.Store(r)
+}
+
+// redact scrubs e in place and returns the number of redactions. Fields
+// are copied before the first change, since the map may be shared.
+func (r *redactor) redact(e *Entry) int {
+	var n, c int
+	e.Output, n = r.redactString(e.Output)
+	copied := false
+	for k, v := range e.Fields {
+		redacted := v
+		if r.fields[strings.ToLower(k)] {
+			redacted, c = r.replacement, 1
+		} else if s, ok := fieldText(v); ok {
+			// Values that are not strings are replaced by their scrubbed
+			// text only when it contained a secret.
+			redacted, c = r.redactString(s)
+		} else {
+			continue
+		}
+		if c == 0 {
+			continue
+		}
+		if !copied {
+			e.Fields = maps.Clone(e.Fields)
+			copied = true
+		}
+		e.Fields[k] = redacted
+		n += c
+	}
+	return n
+}
+
+// fieldText returns the text a field value is rendered as, so secrets in
+// errors, byte slices, headers and other non-string values are found too.
+func fieldText(v any) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case error:
+		return v.Error(), true
+	case fmt.Stringer:
+		return v.String(), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+func (r *redactor) redactString(s string) (string, int) {
+	n := 0
+	for _, d := range r.detectors {
+		s = d.re.ReplaceAllStringFunc(s, func(match string) string {
+			if d.valid != nil && !d.valid(match) {
+				return match
+			}
+			n++
+			return r.replacement
+		})
+	}
+	return s, n
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
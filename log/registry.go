@@ -0,0 +1,250 @@
+package log
+
+import (
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// namespaceStats is the registry entry for one namespace. It is updated
+// with atomics so logging only needs the registry's read lock.
+type namespaceStats struct {
+	firstSeen time.Time
+	lastSeen  atomic.Int64 // Unix nanoseconds
+	counts    [LFatal + 1]atomic.Uint64
+	sampled   atomic.Uint64
+	limited   atomic.Uint64
+	redacted  atomic.Uint64
+}
+
+// NamespaceInfo describes a namespace that has been logged to.
+type NamespaceInfo struct {
+	Name      string    `json:"name"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	// Counts holds the number of entries logged at each level, keyed by
+	// level name, e.g. "ERROR". Levels with no entries are omitted.
+	Counts map[string]uint64 `json:"counts"`
+	// Sampled and RateLimited count the entries dropped by sampling and
+	// by the token bucket of a [RateLimit].
+	Sampled     uint64 `json:"sampled"`
+	RateLimited uint64 `json:"rate_limited"`
+	// Redacted counts the secrets scrubbed from the namespace's entries
+	// (see [SetRedaction]).
+	Redacted uint64 `json:"redacted"`
+}
+
+// Total returns the number of entries logged to the namespace.
+func (n NamespaceInfo) Total() uint64 {
+	var total uint64
+	for _, c := range n.Counts {
+		total += c
+	}
+	return total
+}
+
+// GetNamespaceInfo returns the registry entry of every namespace used on
+// the default Hub, sorted by name.
+func GetNamespaceInfo() []NamespaceInfo {
+	return defaultHub.GetNamespaceInfo()
+}
+
+// recordNamespace registers e's namespace and counts e against it.
+// Namespaces that are already known only take the read lock.
+func (h *Hub) recordNamespace(e Entry) {
+	ts := e.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	stats := h.lookupNamespace(e.Namespace, ts)
+	if e.level >= LTrace && e.level <= LFatal {
+		stats.counts[e.level].Add(1)
+	}
+	stats.touch(ts)
+}
+
+// recordDrop counts an entry for namespace that a [RateLimit] dropped.
+func (h *Hub) recordDrop(namespace string, reason dropReason) {
+	now := time.Now()
+	stats := h.lookupNamespace(namespace, now)
+	switch reason {
+	case dropSampled:
+		stats.sampled.Add(1)
+	case dropLimited:
+		stats.limited.Add(1)
+	}
+	stats.touch(now)
+}
+
+// lookupNamespace returns the registry entry for namespace, registering it
+// first seen at ts if needed. Known namespaces only take the read lock.
+func (h *Hub) lookupNamespace(namespace string, ts time.Time) *namespaceStats {
+	h.namespacesMux.RLock()
+	stats := h.namespaces[namespace]
+	h.namespacesMux.RUnlock()
+	if stats != nil {
+		return stats
+	}
+	h.namespacesMux.Lock()
+	defer h.namespacesMux.Unlock()
+	if stats = h.namespaces[namespace]; stats == nil {
+		h.makeRoomLocked(time.Now())
+		stats = &namespaceStats{firstSeen: ts}
+		// Set before inserting, so a concurrent sweep never sees the new
+		// namespace as idle or as the oldest one.
+		stats.lastSeen.Store(ts.UnixNano())
+		h.namespaces[namespace] = stats
+	}
+	return stats
+}
+
+// SetNamespaceLimits bounds the default Hub's namespace registry. See
+// [Hub.SetNamespaceLimits].
+func SetNamespaceLimits(maxIdle time.Duration, maxNamespaces int) {
+	defaultHub.SetNamespaceLimits(maxIdle, maxNamespaces)
+}
+
+// UnregisterNamespace removes namespace from the default Hub's registry.
+// See [Hub.UnregisterNamespace].
+func UnregisterNamespace(namespace string) bool {
+	return defaultHub.UnregisterNamespace(namespace)
+}
+
+// SetNamespaceLimits bounds the namespace registry, which otherwise keeps
+// every namespace ever logged to. Namespaces not logged to for maxIdle are
+// expired, and once maxNamespaces are registered a new namespace evicts the
+// least recently seen one. Zero disables either limit. Expired namespaces
+// reappear, with fresh counters, the next time they are logged to.
+func (h *Hub) SetNamespaceLimits(maxIdle time.Duration, maxNamespaces int) {
+	h.namespacesMux.Lock()
+	defer h.namespacesMux.Unlock()
+	h.namespaceMaxIdle = max(maxIdle, 0)
+	h.maxNamespaces = max(maxNamespaces, 0)
+	h.expireIdleLocked(time.Now())
+	for h.maxNamespaces > 0 && len(h.namespaces) > h.maxNamespaces {
+		h.evictOldestLocked()
+	}
+}
+
+// UnregisterNamespace removes namespace and its counters from h's registry,
+// e.g. when a per-job namespace is finished. It reports whether namespace
+// was registered. Logging to it again registers it anew.
+func (h *Hub) UnregisterNamespace(namespace string) bool {
+	h.namespacesMux.Lock()
+	defer h.namespacesMux.Unlock()
+	_, ok := h.namespaces[namespace]
+	delete(h.namespaces, namespace)
+	return ok
+}
+
+// makeRoomLocked prepares the registry for a new namespace: it expires idle
+// namespaces, at most once per idle period, and evicts the least recently
+// seen namespace if the registry is full.
+func (h *Hub) makeRoomLocked(now time.Time) {
+	if h.namespaceMaxIdle > 0 && now.Sub(h.lastSweep) >= h.namespaceMaxIdle {
+		h.expireIdleLocked(now)
+	}
+	if h.maxNamespaces > 0 && len(h.namespaces) >= h.maxNamespaces {
+		h.expireIdleLocked(now)
+		for len(h.namespaces) >= h.maxNamespaces {
+			h.evictOldestLocked()
+		}
+	}
+}
+
+// expireIdleLocked removes namespaces idle for longer than the limit.
+func (h *Hub) expireIdleLocked(now time.Time) {
+	h.lastSweep = now
+	if h.namespaceMaxIdle <= 0 {
+		return
+	}
+	cutoff := now.Add(-h.namespaceMaxIdle).UnixNano()
+	for ns, stats := range h.namespaces {
+		if stats.lastSeen.Load() < cutoff {
+			delete(h.namespaces, ns)
+		}
+	}
+}
+
+func (h *Hub) evictOldestLocked() {
+	var (
+		oldest     string
+		oldestSeen int64
+		found      bool
+	)
+	for ns, stats := range h.namespaces {
+		if seen := stats.lastSeen.Load(); !found || seen < oldestSeen {
+			oldest, oldestSeen, found = ns, seen, true
+		}
+	}
+	delete(h.namespaces, oldest)
+}
+
+// expireIdle takes the write lock to expire idle namespaces before the
+// registry is read, so callers never see stale entries.
+func (h *Hub) expireIdle() {
+	h.namespacesMux.Lock()
+	h.expireIdleLocked(time.Now())
+	h.namespacesMux.Unlock()
+}
+
+// touch advances the last seen time to ts.
+func (s *namespaceStats) touch(ts time.Time) {
+	// Entries can arrive out of timestamp order; keep the latest.
+	nanos := ts.UnixNano()
+	for {
+		last := s.lastSeen.Load()
+		if nanos <= last || s.lastSeen.CompareAndSwap(last, nanos) {
+			return
+		}
+	}
+}
+
+func (s *namespaceStats) info(name string) NamespaceInfo {
+	info := NamespaceInfo{
+		Name:        name,
+		FirstSeen:   s.firstSeen,
+		LastSeen:    time.Unix(0, s.lastSeen.Load()),
+		Counts:      make(map[string]uint64),
+		Sampled:     s.sampled.Load(),
+		RateLimited: s.limited.Load(),
+		Redacted:    s.redacted.Load(),
+	}
+	for level := range s.counts {
+		if n := s.counts[level].Load(); n > 0 {
+			info.Counts[Level(level).String()] = n
+		}
+	}
+	return info
+}
+
+// GetNamespaces returns a list of all namespaces that have been used on h.
+func (h *Hub) GetNamespaces() []string {
+	h.expireIdle()
+	h.namespacesMux.RLock()
+	defer h.namespacesMux.RUnlock()
+
+	result := make([]string, 0, len(h.namespaces))
+	for ns := range h.namespaces {
+		result = append(result, ns)
+	}
+	return result
+}
+
+// GetNamespaceInfo returns the registry entry of every namespace used on
+// h, sorted by name: when it was first and last logged to, and how many
+// entries it has produced at each level.
+func (h *Hub) GetNamespaceInfo() []NamespaceInfo {
+	h.expireIdle()
+	h.namespacesMux.RLock()
+	result := make([]NamespaceInfo, 0, len(h.namespaces))
+	for ns, stats := range h.namespaces {
+		result = append(result, stats.info(ns))
+	}
+	h.namespacesMux.RUnlock()
+	slices.SortFunc(result, func(a, b NamespaceInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
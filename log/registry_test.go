@@ -0,0 +1,127 @@
+package log
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNamespaceInfo(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	base := time.Date(2024, 11, 10, 15, 0, 0, 0, time.UTC)
+	for i, level := range []string{"INFO", "INFO", "ERROR", "WARN"} {
+		h.Broadcast(Entry{
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Output:    "x",
+			Level:     level,
+			Namespace: "registry",
+		})
+	}
+	// An entry with an older timestamp does not move LastSeen back.
+	h.Broadcast(Entry{Timestamp: base, Level: "DEBUG", Namespace: "registry"})
+	h.NewLogger("another").Errorf("%s", "other")
+
+	infos := h.GetNamespaceInfo()
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name
+	}
+	if !slices.Equal(names, []string{"another", "registry"}) {
+		t.Fatalf("namespaces = %v, want [another registry]", names)
+	}
+	info := infos[1]
+	if !info.FirstSeen.Equal(base) {
+		t.Errorf("FirstSeen = %v, want %v", info.FirstSeen, base)
+	}
+	if want := base.Add(3 * time.Minute); !info.LastSeen.Equal(want) {
+		t.Errorf("LastSeen = %v, want %v", info.LastSeen, want)
+	}
+	want := map[string]uint64{"INFO": 2, "ERROR": 1, "WARN": 1, "DEBUG": 1}
+	for level, n := range want {
+		if info.Counts[level] != n {
+			t.Errorf("Counts[%s] = %d, want %d", level, info.Counts[level], n)
+		}
+	}
+	if len(info.Counts) != len(want) {
+		t.Errorf("Counts = %v, want only levels with entries", info.Counts)
+	}
+	if info.Total() != 5 {
+		t.Errorf("Total = %d, want 5", info.Total())
+	}
+}
+
+func broadcastAt(h *Hub, namespace string, ts time.Time) {
+	h.Broadcast(Entry{Timestamp: ts, Output: "x", Level: "INFO", Namespace: namespace})
+}
+
+func sortedNamespaces(h *Hub) []string {
+	return slices.Sorted(slices.Values(h.GetNamespaces()))
+}
+
+func TestNamespaceIdleExpiry(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	now := time.Now()
+	broadcastAt(h, "stale", now.Add(-2*time.Hour))
+	broadcastAt(h, "fresh", now)
+	h.SetNamespaceLimits(time.Hour, 0)
+	if got := sortedNamespaces(h); !slices.Equal(got, []string{"fresh"}) {
+		t.Errorf("namespaces = %v, want [fresh]", got)
+	}
+	// An expired namespace comes back with fresh counters.
+	broadcastAt(h, "stale", now)
+	for _, info := range h.GetNamespaceInfo() {
+		if info.Name == "stale" && info.Total() != 1 {
+			t.Errorf("re-registered namespace has %d entries, want 1", info.Total())
+		}
+	}
+}
+
+func TestNamespaceMaxSize(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	now := time.Now()
+	broadcastAt(h, "a", now.Add(-3*time.Minute))
+	broadcastAt(h, "b", now.Add(-2*time.Minute))
+	broadcastAt(h, "c", now.Add(-1*time.Minute))
+	h.SetNamespaceLimits(0, 2)
+	if got := sortedNamespaces(h); !slices.Equal(got, []string{"b", "c"}) {
+		t.Errorf("namespaces = %v, want [b c]", got)
+	}
+	broadcastAt(h, "b", now)
+	broadcastAt(h, "d", now)
+	if got := sortedNamespaces(h); !slices.Equal(got, []string{"b", "d"}) {
+		t.Errorf("namespaces = %v, want [b d]", got)
+	}
+}
+
+func TestNewNamespaceNotEvictedBeforeTouch(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	now := time.Now()
+	broadcastAt(h, "old", now.Add(-time.Minute))
+	// The redaction path registers a namespace without touching it; it
+	// must already count as seen at ts.
+	h.lookupNamespace("new", now)
+	h.SetNamespaceLimits(time.Hour, 1)
+	if got := sortedNamespaces(h); !slices.Equal(got, []string{"new"}) {
+		t.Errorf("namespaces = %v, want [new]", got)
+	}
+}
+
+func TestUnregisterNamespace(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.NewLogger("job-1").Info("done")
+	h.NewLogger("job-2").Info("running")
+	if !h.UnregisterNamespace("job-1") {
+		t.Error("UnregisterNamespace returned false for a registered namespace")
+	}
+	if h.UnregisterNamespace("job-1") {
+		t.Error("UnregisterNamespace returned true for an unknown namespace")
+	}
+	if got := sortedNamespaces(h); !slices.Equal(got, []string{"job-2"}) {
+		t.Errorf("namespaces = %v, want [job-2]", got)
+	}
+}
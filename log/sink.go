@@ -0,0 +1,316 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Sink is a destination for log entries, such as a terminal, a file or a
+// remote collector. Each registered Sink is fed by its own [Client], so a
+// slow Sink only holds up logging calls if it was registered with the
+// OverflowBlock policy; otherwise its overflow policy drops entries. Its
+// Write method is only ever called from a single goroutine.
+type Sink interface {
+	// Write outputs a single entry.
+	Write(e Entry) error
+	// Flush commits any buffered output.
+	Flush() error
+	// Close releases any resources held by the Sink. It is called once,
+	// after the final Flush, when the Sink is removed.
+	Close() error
+}
+
+// ErrSinkRegistered is returned by [AddSink] when the Sink is already
+// registered.
+var ErrSinkRegistered = errors.New("sink is already registered")
+
+// ErrSinkNotRegistered is returned by [RemoveSink] when the Sink is not
+// registered.
+var ErrSinkNotRegistered = errors.New("sink is not registered")
+
+type sinkRunner struct {
+	sink   Sink
+	client *Client
+	writer LogWriter
+	stop   chan struct{}
+	done   chan struct{}
+	// flushReq asks the runner to write out what is queued and flush
+	// the sink; the result is sent back on the enclosed channel.
+	flushReq chan chan error
+	failed   atomic.Uint64
+}
+
+// SinkErrorHandler is called with every entry a Sink failed to write and
+// the error its Write returned. It runs on the Sink's goroutine.
+type SinkErrorHandler func(s Sink, e Entry, err error)
+
+var stderrSink *WriterSink
+
+// AddSink registers s to receive every entry at or above level. Optional
+// namespaces restrict the Sink to those namespaces, as with [CreateClient].
+func AddSink(s Sink, level Level, namespaces ...string) error {
+	return defaultHub.AddSink(s, level, namespaces...)
+}
+
+// AddSinkWithOptions registers s, feeding it from a client configured by
+// opts. Use it to give a bulk exporter a larger buffer or a blocking
+// overflow policy.
+func AddSinkWithOptions(s Sink, opts ClientOptions) error {
+	return defaultHub.AddSinkWithOptions(s, opts)
+}
+
+// RemoveSink unregisters s, writes any entries still queued for it, then
+// flushes and closes it.
+func RemoveSink(s Sink) error {
+	return defaultHub.RemoveSink(s)
+}
+
+// SetSinkLevel sets the minimum level delivered to a registered Sink.
+func SetSinkLevel(s Sink, level Level) error {
+	return defaultHub.SetSinkLevel(s, level)
+}
+
+// SinkFailures returns how many entries a registered Sink failed to write.
+func SinkFailures(s Sink) (uint64, error) {
+	return defaultHub.SinkFailures(s)
+}
+
+// SetSinkErrorHandler sets the function called when a Sink on the default
+// Hub fails to write an entry. See [Hub.SetSinkErrorHandler].
+func SetSinkErrorHandler(fn SinkErrorHandler) {
+	defaultHub.SetSinkErrorHandler(fn)
+}
+
+// AddSink registers s on h. See [AddSink].
+func (h *Hub) AddSink(s Sink, level Level, namespaces ...string) error {
+	return h.AddSinkWithOptions(s, ClientOptions{Level: level, Namespaces: namespaces})
+}
+
+// AddSinkWithOptions registers s on h. See [AddSinkWithOptions].
+func (h *Hub) AddSinkWithOptions(s Sink, opts ClientOptions) error {
+	h.sinksMux.Lock()
+	defer h.sinksMux.Unlock()
+	if h.closed.Load() {
+		return ErrHubClosed
+	}
+	if _, ok := h.sinks[s]; ok {
+		return ErrSinkRegistered
+	}
+	c := h.CreateClientWithOptions(opts)
+	r := &sinkRunner{
+		sink:   s,
+		client: c,
+		writer: c.writer,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+
+		flushReq: make(chan chan error),
+	}
+	h.sinks[s] = r
+	go r.run()
+	return nil
+}
+
+// RemoveSink unregisters s from h. See [RemoveSink].
+func (h *Hub) RemoveSink(s Sink) error {
+	h.sinksMux.Lock()
+	r, ok := h.sinks[s]
+	delete(h.sinks, s)
+	h.sinksMux.Unlock()
+	if !ok {
+		return ErrSinkNotRegistered
+	}
+	return r.shutdown()
+}
+
+// SetSinkLevel sets the minimum level delivered to a Sink registered on h.
+func (h *Hub) SetSinkLevel(s Sink, level Level) error {
+	h.sinksMux.Lock()
+	r, ok := h.sinks[s]
+	h.sinksMux.Unlock()
+	if !ok {
+		return ErrSinkNotRegistered
+	}
+	r.client.SetLogLevel(level)
+	return nil
+}
+
+// SinkFailures returns how many entries a Sink registered on h failed to
+// write, e.g. because its file could not be written or an entry could not
+// be formatted.
+func (h *Hub) SinkFailures(s Sink) (uint64, error) {
+	h.sinksMux.Lock()
+	r, ok := h.sinks[s]
+	h.sinksMux.Unlock()
+	if !ok {
+		return 0, ErrSinkNotRegistered
+	}
+	return r.failed.Load(), nil
+}
+
+// SetSinkErrorHandler sets the function called when a Sink on h fails to
+// write an entry. Without a handler, the first failure of each Sink is
+// reported on standard error and later ones are only counted (see
+// [Hub.SinkFailures]). A nil fn restores that default.
+func (h *Hub) SetSinkErrorHandler(fn SinkErrorHandler) {
+	if fn == nil {
+		h.sinkErrors.Store(nil)
+		return
+	}
+	h.sinkErrors.Store(&fn)
+}
+
+// StderrSink returns the built-in Sink that writes to standard error. It is
+// registered at startup; pass it to [RemoveSink] to silence terminal output.
+func StderrSink() *WriterSink {
+	return stderrSink
+}
+
+func (r *sinkRunner) run() {
+	defer close(r.done)
+	for e, ok := r.client.nextBacklog(); ok; e, ok = r.client.nextBacklog() {
+		r.write(e)
+	}
+	for {
+		select {
+		case e := <-r.writer:
+			r.write(e)
+		case reply := <-r.flushReq:
+			r.drain()
+			reply <- r.sink.Flush()
+		case <-r.stop:
+			// Drain whatever was queued before the client was destroyed.
+			r.drain()
+			return
+		}
+	}
+}
+
+// write outputs e, counting and reporting a failure.
+func (r *sinkRunner) write(e Entry) {
+	err := r.sink.Write(e)
+	if err == nil {
+		return
+	}
+	n := r.failed.Add(1)
+	if fn := r.client.hub.sinkErrors.Load(); fn != nil {
+		(*fn)(r.sink, e, err)
+	} else if n == 1 {
+		fmt.Fprintf(os.Stderr, "log-socket: sink %T: write failed: %v; further failures are only counted\n", r.sink, err)
+	}
+}
+
+// drain writes every entry currently queued for the sink.
+func (r *sinkRunner) drain() {
+	for {
+		select {
+		case e := <-r.writer:
+			r.write(e)
+		default:
+			return
+		}
+	}
+}
+
+// flush writes out the entries queued so far and flushes the sink, on the
+// runner's goroutine so Write and Flush never run concurrently.
+func (r *sinkRunner) flush() error {
+	reply := make(chan error, 1)
+	select {
+	case r.flushReq <- reply:
+		return <-reply
+	case <-r.done:
+		// Removed concurrently; shutdown drains and flushes instead.
+		return nil
+	}
+}
+
+func (r *sinkRunner) shutdown() error {
+	r.client.Destroy()
+	close(r.stop)
+	<-r.done
+	return errors.Join(r.sink.Flush(), r.sink.Close())
+}
+
+// flushSinks writes out and flushes every Sink registered on h, leaving
+// them registered.
+func (h *Hub) flushSinks() error {
+	h.sinksMux.Lock()
+	runners := make([]*sinkRunner, 0, len(h.sinks))
+	for _, r := range h.sinks {
+		runners = append(runners, r)
+	}
+	h.sinksMux.Unlock()
+	var errs []error
+	for _, r := range runners {
+		errs = append(errs, r.flush())
+	}
+	return errors.Join(errs...)
+}
+
+// WriterSink is a [Sink] that writes formatted entries to an [io.Writer].
+// The writer is not closed by [WriterSink.Close].
+type WriterSink struct {
+	mux       sync.Mutex
+	w         io.Writer
+	formatter Formatter
+}
+
+// NewWriterSink returns a WriterSink that writes to w using a
+// [TextFormatter].
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w, formatter: &TextFormatter{}}
+}
+
+// SetFormatter changes how the sink renders entries. A nil Formatter
+// restores the default [TextFormatter].
+func (s *WriterSink) SetFormatter(f Formatter) {
+	if f == nil {
+		f = &TextFormatter{}
+	}
+	s.mux.Lock()
+	s.formatter = f
+	s.mux.Unlock()
+}
+
+// Write formats e and writes it to the underlying writer.
+func (s *WriterSink) Write(e Entry) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	b, err := s.formatter.Format(e)
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(b)
+	return err
+}
+
+// Flush flushes the underlying writer if it buffers output.
+func (s *WriterSink) Flush() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if f, ok := s.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close flushes the sink. The underlying writer is left open.
+func (s *WriterSink) Close() error {
+	return s.Flush()
+}
+
+// SetFormatter changes how the stderr sink renders entries, e.g. to
+// [JSONFormatter] for container log collectors.
+func SetFormatter(f Formatter) {
+	stderrSink.SetFormatter(f)
+}
+
+func initStderrSink() {
+	stderrSink = NewWriterSink(os.Stderr)
+	AddSink(stderrSink, LTrace)
+}
@@ -0,0 +1,169 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memorySink records entries in memory for tests.
+type memorySink struct {
+	mux     sync.Mutex
+	entries []Entry
+	flushed int
+	closed  int
+}
+
+func (s *memorySink) Write(e Entry) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+func (s *memorySink) Flush() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.flushed++
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.closed++
+	return nil
+}
+
+func (s *memorySink) outputs() []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	var out []string
+	for _, e := range s.entries {
+		out = append(out, e.Output)
+	}
+	return out
+}
+
+func TestAddRemoveSink(t *testing.T) {
+	s := &memorySink{}
+	if err := AddSink(s, LInfo, "sink-test"); err != nil {
+		t.Fatalf("AddSink: %v", err)
+	}
+	if err := AddSink(s, LInfo); !errors.Is(err, ErrSinkRegistered) {
+		t.Errorf("second AddSink error = %v, want ErrSinkRegistered", err)
+	}
+
+	l := NewLogger("sink-test")
+	l.Debug("below level")
+	l.Info("first")
+	NewLogger("other-ns").Info("other namespace")
+	l.Warn("second")
+
+	if err := RemoveSink(s); err != nil {
+		t.Fatalf("RemoveSink: %v", err)
+	}
+	got := strings.Join(s.outputs(), ",")
+	if got != "first,second" {
+		t.Errorf("sink received %q, want %q", got, "first,second")
+	}
+	if s.flushed != 1 || s.closed != 1 {
+		t.Errorf("flushed=%d closed=%d, want 1 and 1", s.flushed, s.closed)
+	}
+
+	l.Info("after removal")
+	time.Sleep(10 * time.Millisecond)
+	if n := len(s.outputs()); n != 2 {
+		t.Errorf("sink received %d entries after removal, want 2", n)
+	}
+	if err := RemoveSink(s); !errors.Is(err, ErrSinkNotRegistered) {
+		t.Errorf("second RemoveSink error = %v, want ErrSinkNotRegistered", err)
+	}
+}
+
+func TestSetSinkLevel(t *testing.T) {
+	s := &memorySink{}
+	AddSink(s, LTrace, "sink-level")
+	if err := SetSinkLevel(s, LError); err != nil {
+		t.Fatalf("SetSinkLevel: %v", err)
+	}
+	l := NewLogger("sink-level")
+	l.Warn("dropped")
+	l.Error("kept")
+	RemoveSink(s)
+	if got := strings.Join(s.outputs(), ","); got != "kept" {
+		t.Errorf("sink received %q, want %q", got, "kept")
+	}
+	if err := SetSinkLevel(s, LInfo); !errors.Is(err, ErrSinkNotRegistered) {
+		t.Errorf("SetSinkLevel on removed sink error = %v, want ErrSinkNotRegistered", err)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	SetColorEnabled(false)
+	defer SetColorEnabled(true)
+
+	var buf bytes.Buffer
+	s := NewWriterSink(&buf)
+	e := Entry{
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Output:    "hello",
+		File:      "main.go:1",
+		Level:     "INFO",
+		Namespace: "api",
+		Fields:    map[string]any{"k": "v"},
+		level:     LInfo,
+	}
+	if err := s.Write(e); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	want := e.Timestamp.String() + "\tINFO\t[api]\thello k=v\tmain.go:1\n"
+	if buf.String() != want {
+		t.Errorf("WriterSink wrote %q, want %q", buf.String(), want)
+	}
+}
+
+// failingSink fails every write.
+type failingSink struct{ memorySink }
+
+func (s *failingSink) Write(e Entry) error {
+	return errors.New("disk full")
+}
+
+func TestSinkFailures(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	s := &failingSink{}
+	var (
+		mux    sync.Mutex
+		failed []string
+	)
+	h.SetSinkErrorHandler(func(sink Sink, e Entry, err error) {
+		mux.Lock()
+		defer mux.Unlock()
+		if sink == s {
+			failed = append(failed, e.Output+": "+err.Error())
+		}
+	})
+	h.AddSink(s, LTrace)
+	l := h.NewLogger("failing")
+	l.Info("one")
+	l.Info("two")
+	h.Flush()
+
+	if n, err := h.SinkFailures(s); err != nil || n != 2 {
+		t.Errorf("SinkFailures = %d, %v, want 2, nil", n, err)
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	if want := []string{"one: disk full", "two: disk full"}; !slices.Equal(failed, want) {
+		t.Errorf("handler saw %v, want %v", failed, want)
+	}
+	if _, err := h.SinkFailures(&memorySink{}); !errors.Is(err, ErrSinkNotRegistered) {
+		t.Errorf("SinkFailures of unknown sink: err = %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"runtime"
+	"strings"
+)
+
+// DefaultStackDepth is the maximum number of frames captured when
+// [StackTraceConfig.MaxDepth] is zero.
+const DefaultStackDepth = 32
+
+// Frame is one call in a captured stack trace.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// StackTraceConfig configures stack capture on a Hub.
+type StackTraceConfig struct {
+	// MinLevel is the lowest level that captures a stack, e.g. LError.
+	MinLevel Level
+	// MaxDepth caps the number of frames kept. Zero uses
+	// DefaultStackDepth.
+	MaxDepth int
+	// KeepAllFrames keeps Go runtime and log-socket frames, which are
+	// otherwise filtered out.
+	KeepAllFrames bool
+	// Filter, if set, drops frames for which it returns false.
+	Filter func(Frame) bool
+}
+
+// stackConfig is an immutable copy of a StackTraceConfig.
+type stackConfig StackTraceConfig
+
+// modulePrefix is the import path prefix of this module, e.g.
+// "github.com/taigrr/log-socket/v2/", used to filter log-socket frames.
+var modulePrefix = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	// name is "<module>/log.init.func1" or similar.
+	if i := strings.LastIndex(name, "/log."); i >= 0 {
+		return name[:i+1]
+	}
+	return name
+}()
+
+// SetStackTraces enables stack capture on the default Hub. See
+// [Hub.SetStackTraces].
+func SetStackTraces(cfg *StackTraceConfig) {
+	defaultHub.SetStackTraces(cfg)
+}
+
+// SetStackTraces records the caller's stack on every entry logged on h at
+// or above cfg.MinLevel, in [Entry.Stack]. The stack is printed below the
+// entry by [TextFormatter], included by [JSONFormatter] and over the
+// WebSocket, and shown collapsed in the browser viewer. A nil cfg disables
+// capture.
+func (h *Hub) SetStackTraces(cfg *StackTraceConfig) {
+	if cfg == nil {
+		h.stacks.Store(nil)
+		return
+	}
+	sc := stackConfig(*cfg)
+	if sc.MaxDepth <= 0 {
+		sc.MaxDepth = DefaultStackDepth
+	}
+	h.stacks.Store(&sc)
+}
+
+// captureStack returns the calling goroutine's stack, skipping skip frames
+// above its caller and applying the config's filters.
+func (sc *stackConfig) captureStack(skip int) []Frame {
+	pcs := make([]uintptr, sc.MaxDepth+16)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var stack []Frame
+	for len(stack) < sc.MaxDepth {
+		f, more := frames.Next()
+		frame := Frame{Function: f.Function, File: f.File, Line: f.Line}
+		if f.Function != "" && (sc.KeepAllFrames || !internalFrame(frame)) &&
+			(sc.Filter == nil || sc.Filter(frame)) {
+			stack = append(stack, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// internalFrame reports whether f belongs to the Go runtime or to
+// log-socket itself, other than its tests.
+func internalFrame(f Frame) bool {
+	if strings.HasPrefix(f.Function, "runtime.") || strings.HasPrefix(f.Function, "testing.") {
+		return true
+	}
+	return strings.HasPrefix(f.Function, modulePrefix) && !strings.HasSuffix(f.File, "_test.go")
+}
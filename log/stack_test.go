@@ -0,0 +1,96 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func stackHelper(l *Logger) {
+	l.Error("from helper")
+}
+
+func TestStackTraces(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetStackTraces(&StackTraceConfig{MinLevel: LError})
+	c := h.CreateClient()
+	defer c.Destroy()
+
+	l := h.NewLogger("stack")
+	l.Warn("no stack")
+	stackHelper(l)
+
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.Stack != nil {
+		t.Errorf("WARN entry has a stack: %v", e.Stack)
+	}
+	e, ok = getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if len(e.Stack) < 2 {
+		t.Fatalf("stack = %v, want helper and test frames", e.Stack)
+	}
+	if !strings.HasSuffix(e.Stack[0].Function, ".stackHelper") || !strings.HasSuffix(e.Stack[0].File, "stack_test.go") {
+		t.Errorf("top frame = %+v, want stackHelper", e.Stack[0])
+	}
+	if !strings.HasSuffix(e.Stack[1].Function, ".TestStackTraces") {
+		t.Errorf("second frame = %+v, want TestStackTraces", e.Stack[1])
+	}
+	for _, f := range e.Stack {
+		if internalFrame(f) {
+			t.Errorf("internal frame not filtered: %+v", f)
+		}
+	}
+}
+
+func TestStackTraceOptions(t *testing.T) {
+	t.Parallel()
+	h := NewHub()
+	h.SetStackTraces(&StackTraceConfig{MinLevel: LInfo, MaxDepth: 1, KeepAllFrames: true})
+	c := h.CreateClient()
+	defer c.Destroy()
+	h.NewLogger("stack").Info("shallow")
+	e, ok := getEntry(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if len(e.Stack) != 1 || !strings.HasPrefix(e.Stack[0].Function, modulePrefix) {
+		t.Errorf("stack = %+v, want the log-socket frame only", e.Stack)
+	}
+
+	h.SetStackTraces(&StackTraceConfig{Filter: func(f Frame) bool {
+		return !strings.Contains(f.Function, "TestStackTraceOptions")
+	}})
+	h.NewLogger("stack").Info("filtered")
+	e, _ = getEntry(c, time.Second)
+	if len(e.Stack) != 0 {
+		t.Errorf("stack = %+v, want every frame filtered", e.Stack)
+	}
+
+	h.SetStackTraces(nil)
+	h.NewLogger("stack").Error("disabled")
+	e, _ = getEntry(c, time.Second)
+	if e.Stack != nil {
+		t.Errorf("stack captured while disabled: %+v", e.Stack)
+	}
+}
+
+func TestTextFormatterStack(t *testing.T) {
+	e := Entry{
+		Output: "failed",
+		Level:  "ERROR",
+		Stack:  []Frame{{Function: "main.handler", File: "/src/app/handler.go", Line: 42}},
+	}
+	b, err := (&TextFormatter{NoColor: true}).Format(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(b), "\n\tmain.handler\n\t\t/src/app/handler.go:42\n") {
+		t.Errorf("output = %q", b)
+	}
+}
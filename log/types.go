@@ -1,6 +1,10 @@
 package log
 
-import "time"
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 const (
 	LTrace Level = iota
@@ -15,6 +19,10 @@ const (
 
 const DefaultNamespace = "default"
 
+// DefaultBufferSize is the number of entries a client buffers when no size
+// is given.
+const DefaultBufferSize = 1000
+
 // String returns the human-readable name of the log level (e.g. "INFO").
 // It implements [fmt.Stringer].
 func (l Level) String() string {
@@ -45,21 +53,107 @@ type (
 	Level     int
 
 	Client struct {
-		LogLevel    Level    `json:"level"`
-		Namespaces  []string `json:"namespaces"` // Empty slice means all namespaces
+		// LogLevel mirrors the level set with SetLogLevel. Assigning it
+		// directly does not change which entries are delivered.
+		LogLevel Level `json:"level"`
+		// Namespaces holds the namespace patterns the client was created
+		// with; empty means all namespaces. Assigning it directly does not
+		// change which entries are delivered.
+		Namespaces  []string `json:"namespaces"`
+		filter      namespaceFilter
 		writer      LogWriter
-		initialized bool
+		initialized atomic.Bool
+		hub         *Hub
+
+		// config is read lock-free during dispatch and replaced as a
+		// whole by setters holding configMux.
+		config     atomic.Pointer[clientConfig]
+		configMux  sync.Mutex
+		overflowed atomic.Uint64
+		dropped    atomic.Uint64
+
+		// backlog holds replayed history, returned by Get before any
+		// live entry. Live entries numbered minSeq or lower were part
+		// of the replayed history and are skipped.
+		backlog    []Entry
+		backlogMux sync.Mutex
+		minSeq     uint64
+		gap        *Gap
+	}
+	clientConfig struct {
+		level        Level
+		overflow     OverflowPolicy
+		blockTimeout time.Duration
+		sampleRate   int
+	}
+	// ClientOptions configures a client created with
+	// [CreateClientWithOptions]. The zero value matches [CreateClient].
+	ClientOptions struct {
+		// BufferSize is the capacity of the client's entry buffer. Zero
+		// or negative uses DefaultBufferSize.
+		BufferSize int
+		// Level is the minimum level delivered to the client.
+		Level Level
+		// Namespaces restricts the client to these namespaces. Empty
+		// means all namespaces. See [CreateClient] for patterns.
+		Namespaces []string
+		// Overflow decides what happens when the buffer is full.
+		Overflow OverflowPolicy
+		// BlockTimeout is how long OverflowBlock waits for room.
+		BlockTimeout time.Duration
+		// SampleRate is N for OverflowSample.
+		SampleRate int
+		// ReplayLast, when positive, delivers up to this many of the
+		// most recent matching entries from the Hub's history before
+		// live entries. Requires history (see SetHistoryLimits).
+		ReplayLast int
+		// ReplaySince, when non-zero, delivers matching entries from
+		// the Hub's history logged at or after this time before live
+		// entries. Combined with ReplayLast, the last ReplayLast of
+		// those entries are delivered.
+		ReplaySince time.Time
+		// ReplayAfter, when non-zero, delivers matching entries from the
+		// Hub's history whose Seq is greater than this value, letting a
+		// reconnecting consumer resume where it left off. If some of
+		// those entries were already evicted, see [Client.ReplayGap].
+		// Concurrent entries can be delivered out of Seq order, so
+		// resuming after the highest Seq seen can miss a lower one that
+		// was still queued; resuming is best-effort.
+		ReplayAfter uint64
+	}
+	// Gap is a range of sequence numbers, inclusive, that a resuming
+	// client asked for but that were no longer in the Hub's history.
+	Gap struct {
+		From uint64 `json:"from"`
+		To   uint64 `json:"to"`
 	}
 	Entry struct {
-		Timestamp time.Time `json:"timestamp"`
-		Output    string    `json:"output"`
-		File      string    `json:"file"`
-		Level     string    `json:"level"`
-		Namespace string    `json:"namespace"`
-		level     Level
+		Timestamp time.Time      `json:"timestamp"`
+		Output    string         `json:"output"`
+		File      string         `json:"file"`
+		Level     string         `json:"level"`
+		Namespace string         `json:"namespace"`
+		Fields    map[string]any `json:"fields,omitempty"`
+		// Function and Package name the function that logged the entry,
+		// e.g. "(*Server).handle" in "github.com/a/b/api". File is
+		// shortened according to the CallerPolicy.
+		Function string `json:"function,omitempty"`
+		Package  string `json:"package,omitempty"`
+		// Seq is assigned by the Hub when the entry is logged and
+		// increases by one with every entry, across all namespaces.
+		Seq uint64 `json:"seq"`
+		// Stack is the caller's stack, captured for levels configured
+		// with SetStackTraces.
+		Stack []Frame `json:"stack,omitempty"`
+		level Level
 	}
 	Logger struct {
 		FileInfoDepth int
 		Namespace     string
+		fields        map[string]any
+		level         Level
+		hub           *Hub
+		limiter       *limiter
+		caller        CallerPolicy
 	}
 )
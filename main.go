@@ -39,8 +39,10 @@ func generateLogs() {
 func main() {
 	defer logger.Flush()
 	flag.Parse()
+	logger.SetHistoryLimits(1000, 0)
 	http.HandleFunc("/ws", ws.LogSocketHandler)
 	http.HandleFunc("/api/namespaces", ws.NamespacesHandler)
+	http.HandleFunc("/api/levels", ws.LevelsHandler)
 	http.HandleFunc("/", browser.LogSocketViewHandler)
 	go generateLogs()
 	logger.Fatal(http.ListenAndServe(*addr, nil))
@@ -5,9 +5,7 @@ package slog
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
-	"runtime"
 	"strings"
 
 	"github.com/taigrr/log-socket/v2/log"
@@ -20,6 +18,7 @@ import (
 // message as key=value pairs. Groups set via [Handler.WithGroup] prefix
 // attribute keys with "group.".
 type Handler struct {
+	hub       *log.Hub
 	namespace string
 	level     slog.Level
 	attrs     []slog.Attr
@@ -40,6 +39,13 @@ func WithNamespace(ns string) Option {
 	}
 }
 
+// WithHub sends entries to hub instead of the default [log.Hub].
+func WithHub(hub *log.Hub) Option {
+	return func(h *Handler) {
+		h.hub = hub
+	}
+}
+
 // WithLevel sets the minimum slog level the handler will accept.
 func WithLevel(l slog.Level) Option {
 	return func(h *Handler) {
@@ -51,6 +57,7 @@ func WithLevel(l slog.Level) Option {
 // system.  Options may be used to set the namespace and minimum level.
 func NewHandler(opts ...Option) *Handler {
 	h := &Handler{
+		hub:       log.DefaultHub(),
 		namespace: log.DefaultNamespace,
 		level:     slog.LevelDebug,
 	}
@@ -82,27 +89,17 @@ func (h *Handler) Handle(_ context.Context, r slog.Record) error {
 		return true
 	})
 
-	file := "???"
-	if r.PC != 0 {
-		fs := runtime.CallersFrames([]uintptr{r.PC})
-		f, _ := fs.Next()
-		if f.File != "" {
-			short := f.File
-			if idx := strings.LastIndex(short, "/"); idx >= 0 {
-				short = short[idx+1:]
-			}
-			file = fmt.Sprintf("%s:%d", short, f.Line)
-		}
-	}
-
 	e := log.Entry{
 		Timestamp: r.Time,
 		Output:    b.String(),
-		File:      file,
 		Level:     slogLevelToString(r.Level),
 		Namespace: h.namespace,
 	}
-	log.Broadcast(e)
+	if r.PC == 0 {
+		e.File = "???"
+	}
+	h.hub.FillCaller(&e, r.PC)
+	h.hub.Broadcast(e)
 	return nil
 }
 
@@ -126,6 +123,7 @@ func (h *Handler) WithGroup(name string) slog.Handler {
 
 func (h *Handler) clone() *Handler {
 	h2 := &Handler{
+		hub:       h.hub,
 		namespace: h.namespace,
 		level:     h.level,
 		attrs:     make([]slog.Attr, len(h.attrs)),
@@ -3,6 +3,7 @@ package slog
 import (
 	"context"
 	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
@@ -116,3 +117,39 @@ func TestSlogLevelMapping(t *testing.T) {
 		}
 	}
 }
+
+func TestHandler_WithHub(t *testing.T) {
+	hub := log.NewHub()
+	c := hub.CreateClient()
+	defer c.Destroy()
+
+	slog.New(NewHandler(WithHub(hub), WithNamespace("hub-ns"))).Warn("isolated")
+
+	e, ok := getWithTimeout(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if e.Namespace != "hub-ns" || e.Output != "isolated" {
+		t.Errorf("got %q in %q, want %q in %q", e.Output, e.Namespace, "isolated", "hub-ns")
+	}
+}
+
+func TestHandler_Caller(t *testing.T) {
+	hub := log.NewHub()
+	hub.SetCallerPolicy(log.CallerModuleRelative)
+	c := hub.CreateClient()
+	defer c.Destroy()
+
+	slog.New(NewHandler(WithHub(hub))).Info("caller")
+
+	e, ok := getWithTimeout(c, time.Second)
+	if !ok {
+		t.Fatal("timed out")
+	}
+	if !strings.HasPrefix(e.File, "slog/handler_test.go:") {
+		t.Errorf("file = %q, want slog/handler_test.go", e.File)
+	}
+	if e.Function != "TestHandler_Caller" || e.Package != "github.com/taigrr/log-socket/v2/slog" {
+		t.Errorf("function = %q in %q", e.Function, e.Package)
+	}
+}
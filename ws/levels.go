@@ -0,0 +1,138 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	logger "github.com/taigrr/log-socket/v2/log"
+)
+
+// levelSetting is the JSON form of a [logger.LevelSetting].
+type levelSetting struct {
+	Level    string     `json:"level"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+type levelsResponse struct {
+	Global     levelSetting            `json:"global"`
+	Namespaces map[string]levelSetting `json:"namespaces"`
+}
+
+// levelsRequest is the body of a PUT. A namespace mapped to null or ""
+// follows the global level again. RevertAfter, a positive Go duration such
+// as "5m", undoes every change in the request once it elapses.
+type levelsRequest struct {
+	Global      string             `json:"global"`
+	Namespaces  map[string]*string `json:"namespaces"`
+	RevertAfter string             `json:"revert_after"`
+}
+
+// LevelsHandler reads and changes the minimum levels of the default
+// [logger.Hub] at runtime. GET returns the global level and every namespace
+// level; PUT changes them, e.g.
+//
+//	{"namespaces": {"database": "TRACE"}, "revert_after": "5m"}
+//
+// and responds with the resulting levels.
+func LevelsHandler(w http.ResponseWriter, r *http.Request) {
+	serveLevels(logger.DefaultHub(), w, r)
+}
+
+// NewLevelsHandler returns a handler like [LevelsHandler] that controls the
+// levels of hub.
+func NewLevelsHandler(hub *logger.Hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		serveLevels(hub, w, r)
+	}
+}
+
+func serveLevels(hub *logger.Hub, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		if err := updateLevels(hub, r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cfg := hub.Levels()
+	resp := levelsResponse{
+		Global:     toLevelSetting(cfg.Global),
+		Namespaces: make(map[string]levelSetting, len(cfg.Namespaces)),
+	}
+	for ns, s := range cfg.Namespaces {
+		resp.Namespaces[ns] = toLevelSetting(s)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// updateLevels validates the whole request before applying any of it.
+func updateLevels(hub *logger.Hub, r *http.Request) error {
+	var req levelsRequest
+	dec := json.NewDecoder(r.Body)
+	// A misspelled key would otherwise be a silent no-op.
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return err
+	}
+	if req.Global == "" && len(req.Namespaces) == 0 {
+		return errors.New("request changes no level")
+	}
+	var revertAfter time.Duration
+	if req.RevertAfter != "" {
+		d, err := time.ParseDuration(req.RevertAfter)
+		if err != nil {
+			return err
+		}
+		if d <= 0 {
+			return errors.New("revert_after must be positive")
+		}
+		revertAfter = d
+	}
+	var global logger.Level
+	if req.Global != "" {
+		level, err := logger.ParseLevel(req.Global)
+		if err != nil {
+			return err
+		}
+		global = level
+	}
+	levels := make(map[string]logger.Level)
+	for ns, name := range req.Namespaces {
+		if name == nil || *name == "" {
+			continue
+		}
+		level, err := logger.ParseLevel(*name)
+		if err != nil {
+			return err
+		}
+		levels[ns] = level
+	}
+
+	if req.Global != "" {
+		hub.SetGlobalLevelFor(global, revertAfter)
+	}
+	for ns := range req.Namespaces {
+		if level, ok := levels[ns]; ok {
+			hub.SetNamespaceLevelFor(ns, level, revertAfter)
+		} else {
+			hub.ClearNamespaceLevelFor(ns, revertAfter)
+		}
+	}
+	return nil
+}
+
+func toLevelSetting(s logger.LevelSetting) levelSetting {
+	out := levelSetting{Level: s.Level.String()}
+	if !s.RevertAt.IsZero() {
+		out.RevertAt = &s.RevertAt
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/taigrr/log-socket/v2/log"
+)
+
+func doLevels(t *testing.T, hub *logger.Hub, method, body string) (*httptest.ResponseRecorder, levelsResponse) {
+	t.Helper()
+	req := httptest.NewRequest(method, "/api/levels", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	NewLevelsHandler(hub)(w, req)
+	var resp levelsResponse
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+	}
+	return w, resp
+}
+
+func TestLevelsHandler_Get(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/levels", nil)
+	w := httptest.NewRecorder()
+	LevelsHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+}
+
+func TestLevelsHandler_Put(t *testing.T) {
+	hub := logger.NewHub()
+	w, resp := doLevels(t, hub, http.MethodPut,
+		`{"global": "info", "namespaces": {"database": "TRACE"}, "revert_after": "5m"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if resp.Global.Level != "INFO" || resp.Global.RevertAt == nil {
+		t.Errorf("global = %+v, want INFO with revert_at", resp.Global)
+	}
+	if s := resp.Namespaces["database"]; s.Level != "TRACE" || s.RevertAt == nil {
+		t.Errorf("database = %+v, want TRACE with revert_at", s)
+	}
+
+	_, resp = doLevels(t, hub, http.MethodPut, `{"namespaces": {"database": null}}`)
+	if _, ok := resp.Namespaces["database"]; ok {
+		t.Error("database level should be cleared")
+	}
+	if resp.Global.Level != "INFO" {
+		t.Errorf("global = %q, want INFO unchanged", resp.Global.Level)
+	}
+}
+
+func TestLevelsHandler_RevertClear(t *testing.T) {
+	hub := logger.NewHub()
+	hub.SetNamespaceLevel("database", logger.LWarn)
+	w, resp := doLevels(t, hub, http.MethodPut,
+		`{"namespaces": {"database": null}, "revert_after": "50ms"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if _, ok := resp.Namespaces["database"]; ok {
+		t.Error("database level should be cleared")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := hub.Levels().Namespaces["database"]; ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if s := hub.Levels().Namespaces["database"]; s != (logger.LevelSetting{Level: logger.LWarn}) {
+		t.Errorf("database after revert = %+v, want WARN", s)
+	}
+}
+
+func TestLevelsHandler_BadRequest(t *testing.T) {
+	hub := logger.NewHub()
+	for _, body := range []string{
+		`not json`,
+		`{"global": "LOUD"}`,
+		`{"namespaces": {"a": "TRACE", "b": "LOUD"}}`,
+		`{"global": "INFO", "revert_after": "soon"}`,
+		`{"global": "INFO", "revert_after": "-5m"}`,
+		`{"global": "INFO", "revert_after": "0s"}`,
+		`{"level": "DEBUG"}`,
+		`{}`,
+		`{"namespaces": {}, "revert_after": "5m"}`,
+	} {
+		w, _ := doLevels(t, hub, http.MethodPut, body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+	// Nothing from the rejected requests was applied.
+	if cfg := hub.Levels(); cfg.Global.Level != logger.LTrace || len(cfg.Namespaces) != 0 {
+		t.Errorf("levels changed by invalid requests: %+v", cfg)
+	}
+}
+
+func TestLevelsHandler_MethodNotAllowed(t *testing.T) {
+	w, _ := doLevels(t, logger.NewHub(), http.MethodPost, `{}`)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", w.Code)
+	}
+}
@@ -8,10 +8,29 @@ import (
 )
 
 // NamespacesHandler returns a JSON list of all namespaces that have been used
+// under "namespaces", and under "details" each namespace's first and last
+// seen times and per-level entry counts (see [logger.NamespaceInfo]).
 func NamespacesHandler(w http.ResponseWriter, r *http.Request) {
-	namespaces := logger.GetNamespaces()
+	serveNamespaces(logger.DefaultHub(), w)
+}
+
+// NewNamespacesHandler returns a handler like [NamespacesHandler] that lists
+// the namespaces used on hub.
+func NewNamespacesHandler(hub *logger.Hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		serveNamespaces(hub, w)
+	}
+}
+
+func serveNamespaces(hub *logger.Hub, w http.ResponseWriter) {
+	details := hub.GetNamespaceInfo()
+	namespaces := make([]string, len(details))
+	for i, info := range details {
+		namespaces[i] = info.Name
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"namespaces": namespaces,
+		"details":    details,
 	})
 }
@@ -60,3 +60,51 @@ func TestNamespacesHandler_ResponseFormat(t *testing.T) {
 		t.Error("response missing 'namespaces' key")
 	}
 }
+
+func TestNewNamespacesHandler_Hub(t *testing.T) {
+	hub := logger.NewHub()
+	hub.NewLogger("private-ns").Info("register namespace")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/namespaces", nil)
+	w := httptest.NewRecorder()
+	NewNamespacesHandler(hub)(w, req)
+
+	var result struct {
+		Namespaces []string `json:"namespaces"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(result.Namespaces) != 1 || result.Namespaces[0] != "private-ns" {
+		t.Errorf("namespaces = %v, want [private-ns]", result.Namespaces)
+	}
+}
+
+func TestNamespacesHandler_Details(t *testing.T) {
+	hub := logger.NewHub()
+	l := hub.NewLogger("detail-ns")
+	l.Info("one")
+	l.Info("two")
+	l.Error("three")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/namespaces", nil)
+	w := httptest.NewRecorder()
+	NewNamespacesHandler(hub)(w, req)
+
+	var result struct {
+		Details []logger.NamespaceInfo `json:"details"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(result.Details) != 1 {
+		t.Fatalf("details = %v, want one namespace", result.Details)
+	}
+	info := result.Details[0]
+	if info.Name != "detail-ns" || info.Counts["INFO"] != 2 || info.Counts["ERROR"] != 1 {
+		t.Errorf("details = %+v", info)
+	}
+	if info.FirstSeen.IsZero() || info.LastSeen.Before(info.FirstSeen) {
+		t.Errorf("first seen %v, last seen %v", info.FirstSeen, info.LastSeen)
+	}
+}
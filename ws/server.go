@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	logger "github.com/taigrr/log-socket/v2/log"
@@ -12,6 +14,11 @@ import (
 
 var upgrader = websocket.Upgrader{} // use default options
 
+// gapMessage tells a resuming client which sequence numbers it missed.
+type gapMessage struct {
+	Gap logger.Gap `json:"gap"`
+}
+
 // SetUpgrader replaces the default [websocket.Upgrader] used by
 // [LogSocketHandler].
 func SetUpgrader(u websocket.Upgrader) {
@@ -19,28 +26,94 @@ func SetUpgrader(u websocket.Upgrader) {
 }
 
 // LogSocketHandler upgrades the HTTP connection to a WebSocket and streams
-// log entries to the client. An optional "namespaces" query parameter
-// (comma-separated) filters which namespaces the client receives.
+// log entries from the default [logger.Hub] to the client. An optional
+// "namespaces" query parameter (comma-separated) filters which namespaces
+// the client receives; it accepts the same glob patterns and !exclusions
+// as [logger.CreateClient], e.g. "payments.*,!payments.health". When the
+// Hub keeps history, "replay=N" sends the last N matching entries and
+// "since=<RFC 3339 time>" sends the entries logged since that time before
+// live streaming starts. A reconnecting client passes "after=<seq>" with
+// the highest sequence number it received to resume where it left off; if
+// some of the requested entries were already evicted, a
+// {"gap":{"from":N,"to":M}} message is sent first. Resuming is best-effort
+// under concurrent logging: entries can arrive out of sequence order, and
+// one numbered below the resume point that had not arrived when the
+// connection dropped is neither replayed nor reported as a gap.
 func LogSocketHandler(w http.ResponseWriter, r *http.Request) {
+	serveLogSocket(logger.DefaultHub(), logger.ClientOptions{}, w, r)
+}
+
+// HandlerOption configures a handler created by [NewLogSocketHandler].
+type HandlerOption func(*logger.ClientOptions)
+
+// WithClientOptions sets the defaults for the client created for each
+// WebSocket subscriber, such as BufferSize and Overflow, e.g. a small
+// buffer with OverflowDropOldest for browser tabs. Namespaces come from the
+// query parameters, which also override the replay settings when given.
+func WithClientOptions(opts logger.ClientOptions) HandlerOption {
+	return func(o *logger.ClientOptions) {
+		*o = opts
+	}
+}
+
+// NewLogSocketHandler returns a handler like [LogSocketHandler] that streams
+// entries from hub instead of the default Hub.
+func NewLogSocketHandler(hub *logger.Hub, opts ...HandlerOption) http.HandlerFunc {
+	var defaults logger.ClientOptions
+	for _, o := range opts {
+		o(&defaults)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		serveLogSocket(hub, defaults, w, r)
+	}
+}
+
+func serveLogSocket(hub *logger.Hub, opts logger.ClientOptions, w http.ResponseWriter, r *http.Request) {
 	// Get namespaces from query parameter, comma-separated.
 	// Empty or missing means all namespaces.
-	namespacesParam := r.URL.Query().Get("namespaces")
+	query := r.URL.Query()
+	namespacesParam := query.Get("namespaces")
 	var namespaces []string
 	if namespacesParam != "" {
 		namespaces = strings.Split(namespacesParam, ",")
 	}
+	opts.Namespaces = namespaces
+	if replay := query.Get("replay"); replay != "" {
+		n, err := strconv.Atoi(replay)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid replay parameter", http.StatusBadRequest)
+			return
+		}
+		opts.ReplayLast = n
+	}
+	if since := query.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			http.Error(w, "invalid since parameter", http.StatusBadRequest)
+			return
+		}
+		opts.ReplaySince = t
+	}
+	if after := query.Get("after"); after != "" {
+		seq, err := strconv.ParseUint(after, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid after parameter", http.StatusBadRequest)
+			return
+		}
+		opts.ReplayAfter = seq
+	}
 
+	log := hub.NewLogger(logger.DefaultNamespace)
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		logger.Error("upgrade:", err)
+		log.Error("upgrade:", err)
 		return
 	}
 	defer conn.Close()
 
-	lc := logger.CreateClient(namespaces...)
+	lc := hub.CreateClientWithOptions(opts)
 	defer lc.Destroy()
-	lc.SetLogLevel(logger.LTrace)
-	logger.Info("Websocket client attached.")
+	log.Info("Websocket client attached.")
 
 	// Start a read pump so the server detects client disconnects promptly.
 	// Without this, a disconnected client is only noticed when WriteMessage
@@ -57,15 +130,29 @@ func LogSocketHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	if gap, ok := lc.ReplayGap(); ok {
+		gapJSON, _ := json.Marshal(gapMessage{Gap: gap})
+		if err := conn.WriteMessage(websocket.TextMessage, gapJSON); err != nil {
+			log.Warn("write:", err)
+			return
+		}
+	}
+
 	for {
 		entry, ok := lc.GetContext(ctx)
 		if !ok {
 			// Context cancelled — client disconnected.
 			return
 		}
-		logJSON, _ := json.Marshal(entry)
+		logJSON, err := json.Marshal(entry)
+		if err != nil {
+			// Skip the entry rather than sending an empty frame the
+			// viewer cannot parse.
+			log.Warn("marshal:", err)
+			continue
+		}
 		if err := conn.WriteMessage(websocket.TextMessage, logJSON); err != nil {
-			logger.Warn("write:", err)
+			log.Warn("write:", err)
 			return
 		}
 	}
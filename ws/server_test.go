@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -125,3 +126,251 @@ func TestLogSocketHandler_NamespaceFilter(t *testing.T) {
 		t.Errorf("output = %q, want to contain 'should arrive'", entry.Output)
 	}
 }
+
+func TestLogSocketHandler_Fields(t *testing.T) {
+	SetUpgrader(websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	})
+	defer SetUpgrader(websocket.Upgrader{})
+
+	server := httptest.NewServer(http.HandlerFunc(LogSocketHandler))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?namespaces=fields-ns"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	logger.NewLogger("fields-ns").With("request_id", "r-1").Info("with fields")
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+
+	var entry logger.Entry
+	if err := json.Unmarshal(message, &entry); err != nil {
+		t.Fatalf("failed to unmarshal entry: %v", err)
+	}
+	if entry.Fields["request_id"] != "r-1" {
+		t.Errorf("fields = %v, want request_id=r-1", entry.Fields)
+	}
+}
+
+func TestNewLogSocketHandler_Hub(t *testing.T) {
+	SetUpgrader(websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	})
+	defer SetUpgrader(websocket.Upgrader{})
+
+	hub := logger.NewHub()
+	server := httptest.NewServer(NewLogSocketHandler(hub))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?namespaces=hub-ns"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	// Entries on the default hub must not reach a client of another hub.
+	logger.NewLogger("hub-ns").Info("default hub")
+	hub.NewLogger("hub-ns").Info("private hub")
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	var entry logger.Entry
+	if err := json.Unmarshal(message, &entry); err != nil {
+		t.Fatalf("failed to unmarshal entry: %v", err)
+	}
+	if entry.Output != "private hub" {
+		t.Errorf("output = %q, want %q", entry.Output, "private hub")
+	}
+}
+
+func TestLogSocketHandler_Replay(t *testing.T) {
+	SetUpgrader(websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	})
+	defer SetUpgrader(websocket.Upgrader{})
+
+	hub := logger.NewHub()
+	hub.SetHistoryLimits(100, 0)
+	l := hub.NewLogger("replay-ns")
+	l.Info("one")
+	l.Info("two")
+	l.Info("three")
+
+	server := httptest.NewServer(NewLogSocketHandler(hub))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?namespaces=replay-ns&replay=2"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for _, want := range []string{"two", "three"} {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("failed to read message: %v", err)
+		}
+		var entry logger.Entry
+		if err := json.Unmarshal(message, &entry); err != nil {
+			t.Fatalf("failed to unmarshal entry: %v", err)
+		}
+		if entry.Output != want {
+			t.Errorf("output = %q, want %q", entry.Output, want)
+		}
+	}
+}
+
+func TestLogSocketHandler_InvalidReplay(t *testing.T) {
+	for _, query := range []string{"replay=abc", "replay=-1", "since=yesterday", "after=-1"} {
+		req := httptest.NewRequest(http.MethodGet, "/ws?"+query, nil)
+		w := httptest.NewRecorder()
+		LogSocketHandler(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestLogSocketHandler_ResumeAfterGap(t *testing.T) {
+	SetUpgrader(websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	})
+	defer SetUpgrader(websocket.Upgrader{})
+
+	hub := logger.NewHub()
+	hub.SetHistoryLimits(2, 0)
+	l := hub.NewLogger("resume-ns")
+	for i := 0; i < 5; i++ {
+		l.Info("entry")
+	}
+
+	server := httptest.NewServer(NewLogSocketHandler(hub))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?namespaces=resume-ns&after=1"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	var gap struct {
+		Gap *logger.Gap `json:"gap"`
+	}
+	if err := json.Unmarshal(message, &gap); err != nil {
+		t.Fatalf("failed to unmarshal gap: %v", err)
+	}
+	if gap.Gap == nil || *gap.Gap != (logger.Gap{From: 2, To: 3}) {
+		t.Fatalf("gap message = %s, want from 2 to 3", message)
+	}
+	for _, want := range []uint64{4, 5} {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("failed to read message: %v", err)
+		}
+		var entry logger.Entry
+		if err := json.Unmarshal(message, &entry); err != nil {
+			t.Fatalf("failed to unmarshal entry: %v", err)
+		}
+		if entry.Seq != want {
+			t.Errorf("seq = %d, want %d", entry.Seq, want)
+		}
+	}
+}
+
+func TestLogSocketHandler_NamespacePatterns(t *testing.T) {
+	SetUpgrader(websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	})
+	defer SetUpgrader(websocket.Upgrader{})
+
+	hub := logger.NewHub()
+	hub.SetHistoryLimits(100, 0)
+	payments := hub.NewLogger("payments")
+	payments.Named("health").Info("health")
+	hub.NewLogger("orders").Info("orders")
+	payments.Named("stripe").Info("stripe")
+
+	server := httptest.NewServer(NewLogSocketHandler(hub))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?replay=10&namespaces=" +
+		url.QueryEscape("payments.*,!payments.health")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	var entry logger.Entry
+	if err := json.Unmarshal(message, &entry); err != nil {
+		t.Fatalf("failed to unmarshal entry: %v", err)
+	}
+	if entry.Namespace != "payments.stripe" {
+		t.Errorf("namespace = %q, want payments.stripe", entry.Namespace)
+	}
+}
+
+func TestNewLogSocketHandler_ClientOptions(t *testing.T) {
+	SetUpgrader(websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	})
+	defer SetUpgrader(websocket.Upgrader{})
+
+	hub := logger.NewHub()
+	hub.SetHistoryLimits(100, 0)
+	l := hub.NewLogger("opts-ns")
+	l.Info("below the subscriber level")
+	l.Warn("delivered")
+
+	server := httptest.NewServer(NewLogSocketHandler(hub, WithClientOptions(logger.ClientOptions{
+		BufferSize: 8,
+		Overflow:   logger.OverflowDropOldest,
+		Level:      logger.LWarn,
+	})))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?namespaces=opts-ns&replay=10"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	var entry logger.Entry
+	if err := json.Unmarshal(message, &entry); err != nil {
+		t.Fatalf("failed to unmarshal entry: %v", err)
+	}
+	if entry.Output != "delivered" {
+		t.Errorf("output = %q, want the WARN entry only", entry.Output)
+	}
+}